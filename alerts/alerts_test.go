@@ -0,0 +1,150 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"mashinki/parser"
+	"mashinki/search"
+	"mashinki/storage"
+	"testing"
+	"time"
+)
+
+func testCars(ids ...string) []search.Car {
+	var cars []search.Car
+	for _, id := range ids {
+		cars = append(cars, search.Car{Listing: parser.Listing{CarId: id}})
+	}
+	return cars
+}
+
+func TestStore(t *testing.T) {
+	file := storage.Open(t.TempDir(), "subscriptions.json")
+	store, err := NewStore(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sub, err := store.Add(Subscription{ChatID: 42, Criteria: search.Criteria{Brand: "aodi"}})
+	if err != nil || sub.ID != 1 {
+		t.Fatalf("unexpected subscription %+v, %v", sub, err)
+	}
+	for i := 1; i < MaxPerUser; i++ {
+		if _, err := store.Add(Subscription{ChatID: 42}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := store.Add(Subscription{ChatID: 42}); !errors.Is(err, ErrTooMany) {
+		t.Errorf("expected ErrTooMany, got %v", err)
+	}
+
+	if ok, _ := store.SetPaused(7, sub.ID, true); ok {
+		t.Errorf("other chat must not manage the subscription")
+	}
+	if ok, err := store.SetPaused(42, sub.ID, true); !ok || err != nil {
+		t.Errorf("error while pausing: %v", err)
+	}
+	if ok, err := store.Remove(42, 2); !ok || err != nil {
+		t.Errorf("error while removing: %v", err)
+	}
+	if err := store.Checked(3, []string{"a", "b", "a"}, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	// everything is kept after restart
+	store, err = NewStore(storage.Open(t.TempDir(), "other.json"))
+	if err != nil || len(store.List(42)) != 0 {
+		t.Fatalf("new file should be empty")
+	}
+	store, err = NewStore(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	subs := store.List(42)
+	if len(subs) != MaxPerUser-1 || !subs[0].Paused || subs[0].Criteria.Brand != "aodi" {
+		t.Errorf("unexpected subscriptions after reload: %+v", subs)
+	}
+	if subs[1].ID != 3 || len(subs[1].Seen) != 2 || !subs[1].Primed {
+		t.Errorf("seen cars are not saved: %+v", subs[1])
+	}
+	if next, _ := store.Add(Subscription{ChatID: 7}); next.ID != MaxPerUser+1 {
+		t.Errorf("ids must not be reused, got %d", next.ID)
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	store, err := NewStore(storage.Open(t.TempDir(), "subscriptions.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub, _ := store.Add(Subscription{ChatID: 42})
+	paused, _ := store.Add(Subscription{ChatID: 42})
+	store.SetPaused(42, paused.ID, true)
+
+	listed := testCars("1", "2")
+	var rejected []string
+	var findErr error
+	var notified [][]search.Car
+	var checked []int64
+
+	s := NewScheduler(store, time.Hour,
+		func(_ context.Context, sub Subscription) ([]string, error) {
+			checked = append(checked, sub.ID)
+			if findErr != nil {
+				return nil, findErr
+			}
+			// every listing is remembered, even over the limit of calculated cars
+			return []string{"1", "2", "4"}, nil
+		},
+		func(_ context.Context, sub Subscription) (search.Found, error) {
+			checked = append(checked, sub.ID)
+			return search.Found{Cars: listed, Rejected: rejected}, findErr
+		},
+		func(sub Subscription, cars []search.Car) {
+			notified = append(notified, cars)
+		})
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	// a failed first check doesn't prime the subscription
+	findErr = errors.New("che168 is down")
+	s.checkDue(context.Background())
+	if subs := store.List(42); subs[0].Primed || len(checked) != 1 {
+		t.Fatalf("failed check should not prime the subscription: %+v", subs[0])
+	}
+	s.checkDue(context.Background())
+	if len(checked) != 1 {
+		t.Errorf("failed check should be retried after the interval")
+	}
+
+	// the first check only remembers listed cars
+	findErr = nil
+	now = now.Add(time.Hour)
+	s.checkDue(context.Background())
+	if len(notified) != 0 || len(checked) != 2 || checked[1] != sub.ID {
+		t.Fatalf("first check should be silent and skip paused subscriptions: %v, %v", notified, checked)
+	}
+
+	// not due yet
+	s.checkDue(context.Background())
+	if len(checked) != 2 {
+		t.Errorf("subscription checked before the interval")
+	}
+
+	now = now.Add(time.Hour)
+	listed = testCars("2", "3", "4")
+	rejected = []string{"5"}
+	s.checkDue(context.Background())
+	if len(notified) != 1 || len(notified[0]) != 1 || notified[0][0].Listing.CarId != "3" {
+		t.Fatalf("expected only the new car, got %+v", notified)
+	}
+	if subs := store.List(42); !subs[0].IsSeen("5") {
+		t.Errorf("rejected cars should not be calculated again")
+	}
+
+	now = now.Add(time.Hour)
+	s.checkDue(context.Background())
+	if len(notified) != 1 {
+		t.Errorf("seen cars were sent again")
+	}
+}
@@ -0,0 +1,111 @@
+package alerts
+
+import (
+	"context"
+	"mashinki/logging"
+	"mashinki/search"
+	"time"
+)
+
+// checkEvery is how often the scheduler looks for due subscriptions
+const checkEvery = time.Minute
+
+// Scheduler checks subscriptions periodically. The time of the last check is stored
+// with the subscription, so the schedule survives restarts of the bot.
+type Scheduler struct {
+	store    *Store
+	interval time.Duration
+
+	// list returns IDs of all listings of the subscription for the first check
+	list func(ctx context.Context, sub Subscription) ([]string, error)
+	// find returns cars of the subscription skipping seen ones
+	find func(ctx context.Context, sub Subscription) (search.Found, error)
+	// notify sends new cars to the chat of the subscription
+	notify func(sub Subscription, cars []search.Car)
+
+	now func() time.Time
+}
+
+// NewScheduler creates a scheduler checking every subscription once in the interval
+func NewScheduler(store *Store, interval time.Duration,
+	list func(ctx context.Context, sub Subscription) ([]string, error),
+	find func(ctx context.Context, sub Subscription) (search.Found, error),
+	notify func(sub Subscription, cars []search.Car)) *Scheduler {
+
+	return &Scheduler{store: store, interval: interval, list: list, find: find, notify: notify, now: time.Now}
+}
+
+// Run checks due subscriptions until the context is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(min(checkEvery, s.interval))
+	defer ticker.Stop()
+
+	for {
+		s.checkDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkDue checks all subscriptions waiting for the interval
+func (s *Scheduler) checkDue(ctx context.Context) {
+	for _, sub := range s.store.Due(s.now(), s.interval) {
+		if ctx.Err() != nil {
+			return
+		}
+		s.check(ctx, sub)
+	}
+}
+
+// check finds new cars of the subscription. Listings found by the first successful check
+// were listed before the subscription, so they are remembered without notification.
+// Failed checks are retried after the interval.
+func (s *Scheduler) check(ctx context.Context, sub Subscription) {
+	var ids []string
+	var err error
+	if sub.Primed {
+		ids, err = s.checkNew(ctx, sub)
+	} else {
+		ids, err = s.list(ctx, sub)
+	}
+	if ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		logging.DefaultLogger.LogErrorF("Error checking subscription %d: %v", sub.ID, err)
+		if err := s.store.Failed(sub.ID, s.now()); err != nil {
+			logging.DefaultLogger.LogErrorF("Error saving subscription %d: %v", sub.ID, err)
+		}
+		return
+	}
+
+	if err := s.store.Checked(sub.ID, ids, s.now()); err != nil {
+		logging.DefaultLogger.LogErrorF("Error saving subscription %d: %v", sub.ID, err)
+	}
+}
+
+// checkNew sends new cars of the subscription and returns IDs to remember:
+// sent cars and cars that did not match the fuel or the landed cost
+func (s *Scheduler) checkNew(ctx context.Context, sub Subscription) ([]string, error) {
+	found, err := s.find(ctx, sub)
+	if err != nil {
+		return nil, err
+	}
+
+	var fresh []search.Car
+	ids := found.Rejected
+	for _, car := range found.Cars {
+		if !sub.IsSeen(car.Listing.CarId) {
+			fresh = append(fresh, car)
+			ids = append(ids, car.Listing.CarId)
+		}
+	}
+	if len(fresh) > 0 {
+		s.notify(sub, fresh)
+	}
+	return ids, nil
+}
@@ -0,0 +1,196 @@
+// Package alerts keeps saved searches and notifies users about new matching listings
+package alerts
+
+import (
+	"errors"
+	"fmt"
+	"mashinki/i18n"
+	"mashinki/search"
+	"mashinki/storage"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	MaxPerUser = 10  // сколько подписок может быть у одного чата
+	maxSeen    = 500 // сколько последних машин запоминается для каждой подписки
+)
+
+// ErrTooMany means that the chat has MaxPerUser subscriptions already
+var ErrTooMany = errors.New("too many subscriptions")
+
+// Subscription is a saved search of a chat
+type Subscription struct {
+	ID        int64           `json:"id"`
+	ChatID    int64           `json:"chat_id"`
+	Criteria  search.Criteria `json:"criteria"`
+	Profile   string          `json:"profile"` // маршрут для расчета
+	Lang      i18n.Lang       `json:"lang"`
+	Paused    bool            `json:"paused"`
+	CreatedAt time.Time       `json:"created_at"`
+	LastCheck time.Time       `json:"last_check"`
+
+	// машины, найденные при первой проверке, только запоминаются
+	Primed bool     `json:"primed"`
+	Seen   []string `json:"seen"` // id уже найденных машин
+}
+
+// IsSeen tells whether the car was found by the subscription before
+func (s Subscription) IsSeen(carID string) bool {
+	for _, id := range s.Seen {
+		if id == carID {
+			return true
+		}
+	}
+	return false
+}
+
+// Store keeps subscriptions in a JSON file
+type Store struct {
+	file *storage.File
+	mu   sync.Mutex
+	data storeData
+}
+
+type storeData struct {
+	NextID        int64           `json:"next_id"`
+	Subscriptions []*Subscription `json:"subscriptions"`
+}
+
+// NewStore loads subscriptions from the file
+func NewStore(file *storage.File) (*Store, error) {
+	s := &Store{file: file, data: storeData{NextID: 1}}
+	if err := file.Load(&s.data); err != nil {
+		return nil, fmt.Errorf("error while loading subscriptions: %v", err)
+	}
+	return s, nil
+}
+
+// Add saves a new subscription of the chat
+func (s *Store) Add(sub Subscription) (Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.list(sub.ChatID)) >= MaxPerUser {
+		return Subscription{}, ErrTooMany
+	}
+
+	sub.ID = s.data.NextID
+	sub.CreatedAt = time.Now()
+	sub.Primed, sub.Seen, sub.LastCheck = false, nil, time.Time{}
+	s.data.NextID++
+	s.data.Subscriptions = append(s.data.Subscriptions, &sub)
+	return sub, s.save()
+}
+
+// List returns subscriptions of the chat
+func (s *Store) List(chatID int64) []Subscription {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.list(chatID)
+}
+
+func (s *Store) list(chatID int64) []Subscription {
+	var subs []Subscription
+	for _, sub := range s.data.Subscriptions {
+		if sub.ChatID == chatID {
+			subs = append(subs, *sub)
+		}
+	}
+	return subs
+}
+
+// Remove deletes the subscription of the chat, returns false if there is no such subscription
+func (s *Store) Remove(chatID, id int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, sub := range s.data.Subscriptions {
+		if sub.ID == id && sub.ChatID == chatID {
+			s.data.Subscriptions = append(s.data.Subscriptions[:i], s.data.Subscriptions[i+1:]...)
+			return true, s.save()
+		}
+	}
+	return false, nil
+}
+
+// SetPaused pauses or resumes the subscription of the chat
+func (s *Store) SetPaused(chatID, id int64, paused bool) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub := s.find(id)
+	if sub == nil || sub.ChatID != chatID {
+		return false, nil
+	}
+	sub.Paused = paused
+	return true, s.save()
+}
+
+// Due returns active subscriptions not checked for the interval, the longest waiting go first
+func (s *Store) Due(now time.Time, interval time.Duration) []Subscription {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []Subscription
+	for _, sub := range s.data.Subscriptions {
+		if !sub.Paused && now.Sub(sub.LastCheck) >= interval {
+			due = append(due, *sub)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].LastCheck.Before(due[j].LastCheck)
+	})
+	return due
+}
+
+// Failed remembers the time of a failed check, the subscription is checked again after the interval
+func (s *Store) Failed(id int64, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub := s.find(id)
+	if sub == nil {
+		return nil
+	}
+	sub.LastCheck = at
+	return s.save()
+}
+
+// Checked remembers found cars and the time of a successful check
+func (s *Store) Checked(id int64, carIDs []string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub := s.find(id)
+	if sub == nil {
+		// removed during the check
+		return nil
+	}
+
+	sub.LastCheck = at
+	sub.Primed = true
+	for _, carID := range carIDs {
+		if !sub.IsSeen(carID) {
+			sub.Seen = append(sub.Seen, carID)
+		}
+	}
+	if len(sub.Seen) > maxSeen {
+		sub.Seen = append([]string(nil), sub.Seen[len(sub.Seen)-maxSeen:]...)
+	}
+	return s.save()
+}
+
+func (s *Store) find(id int64) *Subscription {
+	for _, sub := range s.data.Subscriptions {
+		if sub.ID == id {
+			return sub
+		}
+	}
+	return nil
+}
+
+func (s *Store) save() error {
+	return s.file.Save(s.data)
+}
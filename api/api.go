@@ -0,0 +1,346 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"mashinki/logging"
+	"mashinki/parser"
+	"mashinki/taxes"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+//go:embed openapi.yaml
+var openAPISpec []byte
+
+const maxBodySize = 1 << 20
+
+// Server serves the parser and the calculator over HTTP
+type Server struct {
+	httpServer *http.Server
+	apiKeys    [][]byte
+
+	// lookup is replaced in tests to avoid requests to che168
+	lookup func(url string) (parser.CarInfo, error)
+}
+
+// lookupRequest is a body of POST /v1/lookup
+type lookupRequest struct {
+	URL     string `json:"url"`
+	Profile string `json:"profile"`
+	Country string `json:"country"`
+}
+
+// calculateRequest is a body of POST /v1/calculate
+type calculateRequest struct {
+	Price      float64 `json:"price"` // в юанях
+	EngineSize int     `json:"engine_size"`
+	Year       int     `json:"year"`
+	Month      int     `json:"month"`
+	Power      int     `json:"power"`
+	FuelType   string  `json:"fuel_type"`
+	Profile    string  `json:"profile"`
+	Country    string  `json:"country"` // страна растаможки, если маршрут не задан
+	City       string  `json:"city"`    // город продавца как на che168, выбирает стоимость доставки
+}
+
+// budgetRequest is a body of POST /v1/budget
+type budgetRequest struct {
+	Budget     float64 `json:"budget"` // в рублях
+	EngineSize int     `json:"engine_size"`
+	Year       int     `json:"year"`
+	Month      int     `json:"month"`
+	FuelType   string  `json:"fuel_type"`
+	Profile    string  `json:"profile"`
+	Country    string  `json:"country"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewServer creates a server listening on addr. Requests must carry one of apiKeys.
+func NewServer(addr string, apiKeys []string) *Server {
+	s := &Server{
+		lookup: parser.GetCarInfo,
+	}
+	for _, key := range apiKeys {
+		if key = strings.TrimSpace(key); key != "" {
+			s.apiKeys = append(s.apiKeys, []byte(key))
+		}
+	}
+
+	s.httpServer = &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return s
+}
+
+// StartServer creates a server and starts listening in background
+func StartServer(addr string, apiKeys []string) (*Server, error) {
+	s := NewServer(addr, apiKeys)
+	if len(s.apiKeys) == 0 {
+		return nil, errors.New("no API keys configured")
+	}
+
+	go func() {
+		log.Printf("API server listening on %s", addr)
+		if err := s.httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logging.DefaultLogger.LogErrorF("API server error: %v", err)
+		}
+	}()
+
+	return s, nil
+}
+
+// Stop shuts the server down waiting for active requests
+func (s *Server) Stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := s.httpServer.Shutdown(ctx); err != nil {
+		logging.DefaultLogger.LogErrorF("Error while stopping API server: %v", err)
+	}
+}
+
+// Handler returns the router with all endpoints
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/openapi.yaml", s.handleOpenAPI)
+	mux.Handle("POST /v1/lookup", s.auth(http.HandlerFunc(s.handleLookup)))
+	mux.Handle("POST /v1/calculate", s.auth(http.HandlerFunc(s.handleCalculate)))
+	mux.Handle("POST /v1/budget", s.auth(http.HandlerFunc(s.handleBudget)))
+	return mux
+}
+
+// auth checks the X-API-Key header
+func (s *Server) auth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.validKey(r.Header.Get("X-API-Key")) {
+			writeError(w, http.StatusUnauthorized, "invalid API key")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// validKey compares the key with every configured key in constant time,
+// so response time does not tell how much of a key was guessed
+func (s *Server) validKey(key string) bool {
+	valid := 0
+	for _, k := range s.apiKeys {
+		valid |= subtle.ConstantTimeCompare([]byte(key), k)
+	}
+	return valid == 1
+}
+
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/yaml")
+	w.Write(openAPISpec)
+}
+
+func (s *Server) handleLookup(w http.ResponseWriter, r *http.Request) {
+	var req lookupRequest
+	if err := decodeBody(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := validateListingURL(req.URL); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	profile, err := resolveProfile(req.Profile, req.Country)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	carInfo, err := s.lookup(req.URL)
+	if errors.Is(err, parser.ErrListingRemoved) {
+		writeError(w, http.StatusGone, "listing was removed")
+		return
+	}
+	if err != nil {
+		logging.DefaultLogger.LogErrorF("API: error getting car info: %v", err)
+		message := "failed to get car info"
+		if errors.Is(err, parser.ErrLayoutChanged) {
+			message = "che168 page layout changed"
+		}
+		writeError(w, http.StatusBadGateway, message)
+		return
+	}
+
+	result, err := taxes.CalculateChecked(carInfo, profile)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) handleCalculate(w http.ResponseWriter, r *http.Request) {
+	var req calculateRequest
+	if err := decodeBody(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := req.validate(); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	profile, err := resolveProfile(req.Profile, req.Country)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	month := req.Month
+	if month == 0 {
+		month = 1
+	}
+	carInfo := parser.CarInfo{
+		Price:      req.Price,
+		EngineSize: req.EngineSize,
+		Year:       fmt.Sprintf("%d-%02d", req.Year, month),
+		FuelType:   req.FuelType,
+	}
+	carInfo.Seller.City = req.City
+	carInfo.SetPower(req.Power)
+
+	writeJSON(w, http.StatusOK, taxes.Calculate(carInfo, profile))
+}
+
+func (s *Server) handleBudget(w http.ResponseWriter, r *http.Request) {
+	var req budgetRequest
+	if err := decodeBody(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := req.validate(); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	profile, err := resolveProfile(req.Profile, req.Country)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	month := req.Month
+	if month == 0 {
+		month = 1
+	}
+	carInfo := parser.CarInfo{
+		EngineSize: req.EngineSize,
+		Year:       fmt.Sprintf("%d-%02d", req.Year, month),
+		FuelType:   req.FuelType,
+	}
+
+	result, err := taxes.MaxPrice(req.Budget, carInfo, profile)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (req calculateRequest) validate() error {
+	switch {
+	case req.Price <= 0:
+		return errors.New("price must be positive")
+	case req.EngineSize <= 0 || req.EngineSize > 10_000:
+		return errors.New("engine_size must be between 1 and 10000")
+	case req.Year < 1950 || req.Year > time.Now().Year():
+		return fmt.Errorf("year must be between 1950 and %d", time.Now().Year())
+	case req.Month < 0 || req.Month > 12:
+		return errors.New("month must be between 1 and 12")
+	case req.Power < 0:
+		return errors.New("power must not be negative")
+	}
+	return nil
+}
+
+func (req budgetRequest) validate() error {
+	switch {
+	case req.Budget <= 0:
+		return errors.New("budget must be positive")
+	case req.EngineSize <= 0 || req.EngineSize > 10_000:
+		return errors.New("engine_size must be between 1 and 10000")
+	case req.Year < 1950 || req.Year > time.Now().Year():
+		return fmt.Errorf("year must be between 1950 and %d", time.Now().Year())
+	case req.Month < 0 || req.Month > 12:
+		return errors.New("month must be between 1 and 12")
+	}
+	return nil
+}
+
+func validateListingURL(rawURL string) error {
+	if rawURL == "" {
+		return errors.New("url is required")
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return errors.New("url is invalid")
+	}
+	if u.Hostname() != "che168.com" && !strings.HasSuffix(u.Hostname(), ".che168.com") {
+		return errors.New("only che168.com listings are supported")
+	}
+	return nil
+}
+
+// resolveProfile checks the requested profile and country,
+// the country without a profile means the first profile of the country
+func resolveProfile(profile, country string) (string, error) {
+	if profile != "" {
+		p, ok := taxes.GetCostProfile(profile)
+		if !ok {
+			return "", fmt.Errorf("unknown profile %q", profile)
+		}
+		if country != "" && p.Country != country {
+			return "", fmt.Errorf("profile %q is not in country %q", profile, country)
+		}
+		return profile, nil
+	}
+
+	if country == "" {
+		return "", nil
+	}
+	p, ok := taxes.CountryProfile(country)
+	if !ok {
+		return "", fmt.Errorf("unknown country %q", country)
+	}
+	return p.Name, nil
+}
+
+func decodeBody(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("invalid request body: %v", err)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logging.DefaultLogger.LogErrorF("API: error writing response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorResponse{Error: message})
+}
@@ -0,0 +1,213 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mashinki/parser"
+	"mashinki/taxes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testKey = "test-key"
+
+func newTestServer() *Server {
+	s := NewServer("", []string{testKey})
+	s.lookup = func(url string) (parser.CarInfo, error) {
+		if strings.Contains(url, "404") {
+			return parser.CarInfo{}, errors.New("not found")
+		}
+		if strings.Contains(url, "410") {
+			return parser.CarInfo{}, fmt.Errorf("failed to get car config: %w", parser.ErrListingRemoved)
+		}
+		if strings.Contains(url, "502") {
+			return parser.CarInfo{}, fmt.Errorf("failed to get car config: %w", &parser.LayoutError{URL: url, Missing: []parser.Field{parser.FieldPrice}})
+		}
+		if strings.Contains(url, "422") {
+			return parser.CarInfo{Price: 100_000, CarId: "422"}, nil
+		}
+		return parser.CarInfo{
+			FullName:   "Test car",
+			Year:       "2019-05",
+			Price:      100_000,
+			EngineSize: 1998,
+			CarId:      "12345",
+		}, nil
+	}
+	return s
+}
+
+func doRequest(s *Server, method, path, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if key != "" {
+		req.Header.Set("X-API-Key", key)
+	}
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAuth(t *testing.T) {
+	s := newTestServer()
+
+	for _, key := range []string{"", "wrong", testKey[:len(testKey)-1], testKey + "x"} {
+		rec := doRequest(s, http.MethodPost, "/v1/calculate", key, `{}`)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("key %q: expected status %d, got %d", key, http.StatusUnauthorized, rec.Code)
+		}
+	}
+
+	s = NewServer("", []string{"first", " second "})
+	for _, key := range []string{"first", "second"} {
+		if !s.validKey(key) {
+			t.Errorf("key %q should be accepted", key)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	s := newTestServer()
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"ok", `{"url": "https://www.che168.com/dealer/1/12345.html"}`, http.StatusOK},
+		{"mobile", `{"url": "https://m.che168.com/cardetail/index?infoid=12345"}`, http.StatusOK},
+		{"bad json", `{"url": `, http.StatusBadRequest},
+		{"unknown field", `{"link": "https://www.che168.com/1.html"}`, http.StatusBadRequest},
+		{"empty url", `{"url": ""}`, http.StatusUnprocessableEntity},
+		{"other site", `{"url": "https://example.com/1.html"}`, http.StatusUnprocessableEntity},
+		{"unknown profile", `{"url": "https://www.che168.com/1.html", "profile": "mars"}`, http.StatusUnprocessableEntity},
+		{"parser error", `{"url": "https://www.che168.com/404.html"}`, http.StatusBadGateway},
+		{"removed", `{"url": "https://www.che168.com/410.html"}`, http.StatusGone},
+		{"insufficient data", `{"url": "https://www.che168.com/422.html"}`, http.StatusUnprocessableEntity},
+		{"layout changed", `{"url": "https://www.che168.com/502.html"}`, http.StatusBadGateway},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(s, http.MethodPost, "/v1/lookup", testKey, tt.body)
+			if rec.Code != tt.status {
+				t.Fatalf("expected status %d, got %d: %s", tt.status, rec.Code, rec.Body)
+			}
+		})
+	}
+
+	rec := doRequest(s, http.MethodPost, "/v1/lookup", testKey, `{"url": "https://www.che168.com/dealer/1/12345.html"}`)
+	var resp taxes.Result
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("error while decoding response: %v", err)
+	}
+	if resp.Car.CarId != "12345" {
+		t.Errorf("expected car id 12345, got %q", resp.Car.CarId)
+	}
+	if resp.Amount(taxes.KindCustomsDuty) <= 0 || resp.Total <= resp.Amount(taxes.KindCarPrice) {
+		t.Errorf("customs are not calculated: %+v", resp.Items)
+	}
+}
+
+func TestCalculate(t *testing.T) {
+	s := newTestServer()
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"ok", `{"price": 150000, "engine_size": 1998, "year": 2021}`, http.StatusOK},
+		{"with profile", `{"price": 150000, "engine_size": 1998, "year": 2021, "month": 6, "profile": "moscow"}`, http.StatusOK},
+		{"with country", `{"price": 150000, "engine_size": 1998, "year": 2021, "country": "kz"}`, http.StatusOK},
+		{"unknown country", `{"price": 150000, "engine_size": 1998, "year": 2021, "country": "us"}`, http.StatusUnprocessableEntity},
+		{"profile of other country", `{"price": 150000, "engine_size": 1998, "year": 2021, "profile": "moscow", "country": "by"}`, http.StatusUnprocessableEntity},
+		{"no price", `{"engine_size": 1998, "year": 2021}`, http.StatusUnprocessableEntity},
+		{"no engine", `{"price": 150000, "year": 2021}`, http.StatusUnprocessableEntity},
+		{"bad year", `{"price": 150000, "engine_size": 1998, "year": 3000}`, http.StatusUnprocessableEntity},
+		{"bad month", `{"price": 150000, "engine_size": 1998, "year": 2021, "month": 13}`, http.StatusUnprocessableEntity},
+		{"wrong type", `{"price": "a lot", "engine_size": 1998, "year": 2021}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(s, http.MethodPost, "/v1/calculate", testKey, tt.body)
+			if rec.Code != tt.status {
+				t.Fatalf("expected status %d, got %d: %s", tt.status, rec.Code, rec.Body)
+			}
+		})
+	}
+
+	rec := doRequest(s, http.MethodPost, "/v1/calculate", testKey, `{"price": 150000, "engine_size": 1998, "year": 2021, "profile": "moscow"}`)
+	var resp taxes.Result
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("error while decoding response: %v", err)
+	}
+	if resp.Profile != "moscow" {
+		t.Errorf("expected profile moscow, got %q", resp.Profile)
+	}
+	if resp.Car.Year != "2021-01" {
+		t.Errorf("expected year 2021-01, got %q", resp.Car.Year)
+	}
+
+	rec = doRequest(s, http.MethodPost, "/v1/calculate", testKey, `{"price": 150000, "engine_size": 1998, "year": 2021, "country": "kg"}`)
+	resp = taxes.Result{}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("error while decoding response: %v", err)
+	}
+	if resp.Country != "kg" || resp.Profile != "bishkek" {
+		t.Errorf("expected kyrgyz profile, got %q %q", resp.Country, resp.Profile)
+	}
+}
+
+func TestBudget(t *testing.T) {
+	s := newTestServer()
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"ok", `{"budget": 3000000, "engine_size": 1998, "year": 2021}`, http.StatusOK},
+		{"with country", `{"budget": 3000000, "engine_size": 1998, "year": 2021, "country": "kz"}`, http.StatusOK},
+		{"too low", `{"budget": 100000, "engine_size": 1998, "year": 2021}`, http.StatusUnprocessableEntity},
+		{"no budget", `{"engine_size": 1998, "year": 2021}`, http.StatusUnprocessableEntity},
+		{"bad year", `{"budget": 3000000, "engine_size": 1998, "year": 1900}`, http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(s, http.MethodPost, "/v1/budget", testKey, tt.body)
+			if rec.Code != tt.status {
+				t.Fatalf("expected status %d, got %d: %s", tt.status, rec.Code, rec.Body)
+			}
+		})
+	}
+
+	// a new car: the duty jumps from 5.5 to 7.5 €/cm³ above 16 700 €
+	rec := doRequest(s, http.MethodPost, "/v1/budget", testKey, `{"budget": 3000000, "engine_size": 1998, "year": 2024}`)
+	var resp taxes.BudgetResult
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("error while decoding response: %v", err)
+	}
+	if resp.Result.Total > resp.Budget || !resp.AtBandLimit {
+		t.Errorf("expected price at band limit within budget, got %+v", resp)
+	}
+
+	next := taxes.Calculate(parser.CarInfo{Price: resp.MaxPrice + 1, EngineSize: 1998, Year: "2024-01"}, "")
+	if next.Total <= resp.Budget {
+		t.Errorf("price %v is not the maximum: %v fits too", resp.MaxPrice, resp.MaxPrice+1)
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	rec := doRequest(newTestServer(), http.MethodGet, "/v1/openapi.yaml", "", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+	if !strings.HasPrefix(rec.Body.String(), "openapi:") {
+		t.Errorf("unexpected spec body")
+	}
+}
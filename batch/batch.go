@@ -0,0 +1,73 @@
+// Package batch looks up and calculates many listings concurrently
+package batch
+
+import (
+	"context"
+	"mashinki/parser"
+	"mashinki/taxes"
+	"sync"
+)
+
+// Row is a processed listing
+type Row struct {
+	URL    string
+	Result taxes.Result
+	Err    error
+}
+
+// Process looks up and calculates all urls with a bounded number of workers.
+// progress is called after each processed url, possibly concurrently, and may be nil.
+func Process(ctx context.Context, urls []string, workers int, profile string,
+	lookup func(url string) (parser.CarInfo, error), progress func(done, total int)) []Row {
+
+	rows := make([]Row, len(urls))
+	jobs := make(chan int)
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		done int
+	)
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				rows[i].URL = urls[i]
+
+				carInfo, err := lookup(urls[i])
+				if err == nil {
+					rows[i].Result, err = taxes.CalculateChecked(carInfo, profile)
+				}
+				rows[i].Err = err
+
+				mu.Lock()
+				done++
+				n := done
+				mu.Unlock()
+
+				// progress may be slow (e.g. a network call), so it runs outside the lock
+				if progress != nil {
+					progress(n, len(urls))
+				}
+			}
+		}()
+	}
+
+	for i := range urls {
+		if ctx.Err() != nil {
+			rows[i] = Row{URL: urls[i], Err: ctx.Err()}
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			rows[i] = Row{URL: urls[i], Err: ctx.Err()}
+		case jobs <- i:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	return rows
+}
@@ -0,0 +1,54 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"mashinki/parser"
+	"strings"
+	"testing"
+)
+
+func fakeLookup(url string) (parser.CarInfo, error) {
+	if strings.Contains(url, "broken") {
+		return parser.CarInfo{}, errors.New("page not found")
+	}
+	return parser.CarInfo{FullName: "Test", Year: "2020-01", Price: 100_000, EngineSize: 1598}, nil
+}
+
+func TestProcess(t *testing.T) {
+	urls := []string{
+		"https://www.che168.com/dealer/1/1.html",
+		"https://www.che168.com/dealer/1/broken.html",
+		"https://www.che168.com/dealer/1/3.html",
+	}
+
+	var calls int
+	rows := Process(context.Background(), urls, 2, "", fakeLookup, func(done, total int) {
+		calls++
+		if total != len(urls) {
+			t.Errorf("expected total %d, got %d", len(urls), total)
+		}
+	})
+
+	if calls != len(urls) {
+		t.Errorf("expected %d progress calls, got %d", len(urls), calls)
+	}
+	for i, r := range rows {
+		if r.URL != urls[i] {
+			t.Errorf("row %d: expected url %s, got %s", i, urls[i], r.URL)
+		}
+	}
+	if rows[1].Err == nil || rows[0].Err != nil || rows[0].Result.Total <= 0 {
+		t.Errorf("unexpected rows: %+v", rows)
+	}
+}
+
+func TestProcessCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	rows := Process(ctx, []string{"https://www.che168.com/1.html"}, 1, "", fakeLookup, nil)
+	if rows[0].Err == nil {
+		t.Errorf("expected error for cancelled processing")
+	}
+}
@@ -0,0 +1,203 @@
+// Package bulk calculates many listings at once from uploaded files
+package bulk
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"mashinki/batch"
+	"mashinki/i18n"
+	"mashinki/parser"
+	"mashinki/taxes"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// MaxURLs limits the number of listings in one file
+const MaxURLs = 200
+
+var urlRegexp = regexp.MustCompile(`https?://[^\s,;"']*che168\.com[^\s,;"']*`)
+
+// Row is a processed listing
+type Row = batch.Row
+
+// column of the output table
+type column struct {
+	name    string // ключ заголовка bulk.col.<name>
+	numeric bool   // в xlsx пишется числом, чтобы можно было сортировать и суммировать
+}
+
+// columns of the output table
+var columns = []column{
+	{name: "url"}, {name: "id"}, {name: "name"}, {name: "year"}, {name: "mileage"},
+	{name: "price_cny", numeric: true}, {name: "engine", numeric: true},
+	{name: "power", numeric: true}, {name: "power_hp", numeric: true},
+	{name: "drive"}, {name: "fuel"}, {name: "spec_id"}, {name: "band"},
+	{name: "price_rub", numeric: true}, {name: "customs_duty", numeric: true},
+	{name: "customs_fee", numeric: true}, {name: "recycling_fee", numeric: true},
+	{name: "registration_fee", numeric: true}, {name: "expenses", numeric: true},
+	{name: "total", numeric: true}, {name: "error"},
+}
+
+// Errors of ReadURLs about the contents of the file
+var (
+	ErrNoURLs      = errors.New("no che168.com links found")
+	ErrTooManyURLs = errors.New("too many links")
+)
+
+// header returns column titles in the language
+func header(lang i18n.Lang) []string {
+	titles := make([]string, len(columns))
+	for i, c := range columns {
+		titles[i] = i18n.T(lang, "bulk.col."+c.name)
+	}
+	return titles
+}
+
+// ReadURLs extracts che168 listing URLs from a .txt, .csv or .xlsx file.
+// Duplicates are skipped.
+func ReadURLs(filename string, data []byte) ([]string, error) {
+	var text string
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".txt", ".csv":
+		text = string(data)
+
+	case ".xlsx":
+		f, err := excelize.OpenReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("error while opening xlsx: %v", err)
+		}
+		defer f.Close()
+
+		var sb strings.Builder
+		for _, sheet := range f.GetSheetList() {
+			rows, err := f.GetRows(sheet)
+			if err != nil {
+				return nil, fmt.Errorf("error while reading sheet %s: %v", sheet, err)
+			}
+			for _, row := range rows {
+				sb.WriteString(strings.Join(row, " "))
+				sb.WriteByte('\n')
+			}
+		}
+		text = sb.String()
+
+	default:
+		return nil, fmt.Errorf("unsupported file type %q", filepath.Ext(filename))
+	}
+
+	var urls []string
+	seen := make(map[string]bool)
+	for _, u := range urlRegexp.FindAllString(text, -1) {
+		if !seen[u] {
+			seen[u] = true
+			urls = append(urls, u)
+		}
+	}
+
+	if len(urls) == 0 {
+		return nil, ErrNoURLs
+	}
+	if len(urls) > MaxURLs {
+		return nil, fmt.Errorf("%w: %d, maximum is %d", ErrTooManyURLs, len(urls), MaxURLs)
+	}
+	return urls, nil
+}
+
+// values returns the row as table cells in the language
+func values(r Row, lang i18n.Lang) []string {
+	if r.Err != nil {
+		cells := make([]string, len(columns))
+		cells[0] = r.URL
+		cells[len(cells)-1] = r.Err.Error()
+		return cells
+	}
+
+	car := r.Result.Car
+	return []string{
+		r.URL,
+		car.CarId,
+		car.FullName,
+		car.Year,
+		mileageKm(car.Mileage),
+		taxes.Plain.Format(car.Price),
+		strconv.Itoa(car.EngineSize),
+		strconv.Itoa(car.Power),
+		strconv.FormatFloat(parser.KwToHp(car.Power), 'f', 1, 64),
+		car.Drive,
+		car.FuelType,
+		car.SpecID,
+		r.Result.Band.Text(lang),
+		taxes.Plain.Format(r.Result.Amount(taxes.KindCarPrice)),
+		taxes.Plain.Format(r.Result.Amount(taxes.KindCustomsDuty)),
+		taxes.Plain.Format(r.Result.Amount(taxes.KindCustomsFee)),
+		taxes.Plain.Format(r.Result.Amount(taxes.KindRecyclingFee)),
+		taxes.Plain.Format(r.Result.Amount(taxes.KindRegistrationFee)),
+		taxes.Plain.Format(r.Result.Amount(taxes.KindExpense)),
+		taxes.Plain.Format(r.Result.Total),
+		"",
+	}
+}
+
+// WriteCSV renders rows as CSV with column titles in the language
+func WriteCSV(rows []Row, lang i18n.Lang) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write(header(lang))
+	for _, r := range rows {
+		w.Write(values(r, lang))
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("error while writing csv: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteXLSX renders rows as an Excel workbook with column titles in the language
+func WriteXLSX(rows []Row, lang i18n.Lang) ([]byte, error) {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	const sheet = "Sheet1"
+	titles := header(lang)
+	if err := f.SetSheetRow(sheet, "A1", &titles); err != nil {
+		return nil, fmt.Errorf("error while writing xlsx: %v", err)
+	}
+
+	for i, r := range rows {
+		cells := make([]interface{}, 0, len(columns))
+		for j, v := range values(r, lang) {
+			if n, err := strconv.ParseFloat(v, 64); err == nil && columns[j].numeric {
+				cells = append(cells, n)
+			} else {
+				cells = append(cells, v)
+			}
+		}
+
+		cell, _ := excelize.CoordinatesToCellName(1, i+2)
+		if err := f.SetSheetRow(sheet, cell, &cells); err != nil {
+			return nil, fmt.Errorf("error while writing xlsx: %v", err)
+		}
+	}
+
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		return nil, fmt.Errorf("error while writing xlsx: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// mileageKm is the mileage in kilometers, empty if che168 has no mileage
+func mileageKm(m parser.Mileage) string {
+	if !m.Known() {
+		return ""
+	}
+	return strconv.Itoa(m.Km)
+}
@@ -0,0 +1,98 @@
+package bulk
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"mashinki/i18n"
+	"mashinki/parser"
+	"mashinki/taxes"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestReadURLs(t *testing.T) {
+	txt := "https://www.che168.com/dealer/1/1.html\nмусор\nhttps://www.che168.com/dealer/1/1.html https://m.che168.com/cardetail/index?infoid=2"
+	urls, err := ReadURLs("list.txt", []byte(txt))
+	if err != nil {
+		t.Fatalf("error while reading txt: %v", err)
+	}
+	if len(urls) != 2 {
+		t.Errorf("expected 2 unique urls, got %v", urls)
+	}
+
+	urls, err = ReadURLs("list.csv", []byte("id,url\n1,https://www.che168.com/dealer/1/1.html\n2,https://example.com/2.html\n"))
+	if err != nil {
+		t.Fatalf("error while reading csv: %v", err)
+	}
+	if len(urls) != 1 || urls[0] != "https://www.che168.com/dealer/1/1.html" {
+		t.Errorf("unexpected urls from csv: %v", urls)
+	}
+
+	f := excelize.NewFile()
+	f.SetCellValue("Sheet1", "B3", "https://www.che168.com/dealer/1/3.html")
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		t.Fatalf("error while creating xlsx: %v", err)
+	}
+	urls, err = ReadURLs("list.XLSX", buf.Bytes())
+	if err != nil {
+		t.Fatalf("error while reading xlsx: %v", err)
+	}
+	if len(urls) != 1 {
+		t.Errorf("expected 1 url from xlsx, got %v", urls)
+	}
+
+	if _, err := ReadURLs("list.pdf", []byte(txt)); err == nil {
+		t.Errorf("expected error for unsupported file")
+	}
+	if _, err := ReadURLs("list.txt", []byte("no links")); !errors.Is(err, ErrNoURLs) {
+		t.Errorf("expected ErrNoURLs for file without links, got %v", err)
+	}
+}
+
+func TestWriteTables(t *testing.T) {
+	car := parser.CarInfo{FullName: "Test", Year: "2020-01", Price: 100_000, EngineSize: 1598}
+	rows := []Row{
+		{URL: "https://www.che168.com/dealer/1/1.html", Result: taxes.Calculate(car, "")},
+		{URL: "https://www.che168.com/dealer/1/broken.html", Err: errors.New("page not found")},
+	}
+
+	data, err := WriteCSV(rows, i18n.Russian)
+	if err != nil {
+		t.Fatalf("error while writing csv: %v", err)
+	}
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid csv: %v", err)
+	}
+	if records[0][2] != "Название" {
+		t.Errorf("unexpected csv header: %v", records[0])
+	}
+	if len(records) != len(rows)+1 || records[2][len(columns)-1] != "page not found" {
+		t.Errorf("unexpected csv: %v", records)
+	}
+
+	data, err = WriteXLSX(rows, i18n.English)
+	if err != nil {
+		t.Fatalf("error while writing xlsx: %v", err)
+	}
+	f, err := excelize.OpenReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("invalid xlsx: %v", err)
+	}
+	if v, _ := f.GetCellValue("Sheet1", "C2"); v != "Test" {
+		t.Errorf("expected name in C2, got %q", v)
+	}
+	if v, _ := f.GetCellValue("Sheet1", "C1"); v != "Name" {
+		t.Errorf("expected english header in C1, got %q", v)
+	}
+	// price is a number, year stays text
+	if typ, _ := f.GetCellType("Sheet1", "F2"); typ == excelize.CellTypeSharedString || typ == excelize.CellTypeInlineString {
+		t.Errorf("expected numeric price in F2, got type %v", typ)
+	}
+	if typ, _ := f.GetCellType("Sheet1", "D2"); typ != excelize.CellTypeSharedString && typ != excelize.CellTypeInlineString {
+		t.Errorf("expected text year in D2, got type %v", typ)
+	}
+}
@@ -0,0 +1,263 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"mashinki/parser"
+	"mashinki/taxes"
+	"mashinki/translations"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+const usage = `Usage: mashinki <command> [flags]
+
+Commands:
+  bot                       start the Telegram bot (default)
+  lookup <url>              get car info from che168.com and calculate payments
+  calc                      calculate payments for manually entered specs
+  rates                     show exchange rates used in calculations
+  translate <text>          translate Chinese text to Russian
+
+Common flags:
+  -format table|json|csv    output format (default table)
+
+Run 'mashinki <command> -h' for command flags.
+`
+
+// row is one line of table and CSV output
+type row struct {
+	field string
+	value string
+}
+
+// Run executes the CLI command and returns the exit code
+func Run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	var err error
+	switch args[0] {
+	case "lookup":
+		err = runLookup(args[1:], stdout, stderr)
+	case "calc":
+		err = runCalc(args[1:], stdout, stderr)
+	case "rates":
+		err = runRates(args[1:], stdout, stderr)
+	case "translate":
+		err = runTranslate(args[1:], stdout, stderr)
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, usage)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "unknown command %q\n\n%s", args[0], usage)
+		return 2
+	}
+
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "Error:", err)
+		return 1
+	}
+	return 0
+}
+
+func newFlagSet(name string, stderr io.Writer) (*flag.FlagSet, *string) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("format", "table", "output format: table, json or csv")
+	return fs, format
+}
+
+func runLookup(args []string, stdout, stderr io.Writer) error {
+	fs, format := newFlagSet("lookup", stderr)
+	profile := fs.String("profile", "", "cost profile name")
+	costs := fs.String("costs", "", "path to costs config JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("lookup requires exactly one url")
+	}
+	if err := loadCosts(*costs, *profile); err != nil {
+		return err
+	}
+
+	parser.OnLayoutChange(func(err *parser.LayoutError) {
+		fmt.Fprintln(stderr, "Warning: che168 changed the page layout, selectors in parser/layout.go need updating")
+	})
+	defer parser.OnLayoutChange(nil)
+
+	carInfo, err := parser.GetCarInfo(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	result, err := taxes.CalculateChecked(carInfo, *profile)
+	if err != nil {
+		return err
+	}
+	return writeResult(stdout, *format, result)
+}
+
+func runCalc(args []string, stdout, stderr io.Writer) error {
+	fs, format := newFlagSet("calc", stderr)
+	engine := fs.Int("engine", 0, "engine size in cm³")
+	year := fs.Int("year", 0, "production year")
+	month := fs.Int("month", 1, "production month")
+	price := fs.Float64("price", 0, "price in CNY")
+	power := fs.Int("power", 0, "power in kW")
+	profile := fs.String("profile", "", "cost profile name")
+	city := fs.String("city", "", "seller city as on che168 (北京), selects the delivery cost of the profile")
+	costs := fs.String("costs", "", "path to costs config JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	switch {
+	case *engine <= 0:
+		return errors.New("-engine must be positive")
+	case *year <= 0:
+		return errors.New("-year is required")
+	case *month < 1 || *month > 12:
+		return errors.New("-month must be between 1 and 12")
+	case *price <= 0:
+		return errors.New("-price must be positive")
+	}
+	if err := loadCosts(*costs, *profile); err != nil {
+		return err
+	}
+
+	carInfo := parser.CarInfo{
+		Price:      *price,
+		EngineSize: *engine,
+		Year:       fmt.Sprintf("%d-%02d", *year, *month),
+	}
+	carInfo.Seller.City = *city
+	carInfo.SetPower(*power)
+
+	return writeResult(stdout, *format, taxes.Calculate(carInfo, *profile))
+}
+
+func runRates(args []string, stdout, stderr io.Writer) error {
+	fs, format := newFlagSet("rates", stderr)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	current := taxes.CurrentRates()
+	rates := struct {
+		CNY         float64 `json:"cny_rub"`
+		EUR         float64 `json:"eur_rub"`
+		KZT         float64 `json:"kzt_rub"`
+		KGS         float64 `json:"kgs_rub"`
+		BYN         float64 `json:"byn_rub"`
+		BaseUtilFee float64 `json:"base_util_fee_rub"`
+	}{current.CNY, current.EUR, current.KZT, current.KGS, current.BYN, taxes.BaseUtilFee}
+
+	return write(stdout, *format, rates, []row{
+		{"CNY → RUB", taxes.Plain.Format(rates.CNY)},
+		{"EUR → RUB", taxes.Plain.Format(rates.EUR)},
+		{"KZT → RUB", taxes.Plain.Format(rates.KZT)},
+		{"KGS → RUB", taxes.Plain.Format(rates.KGS)},
+		{"BYN → RUB", taxes.Plain.Format(rates.BYN)},
+		{"Базовый утильсбор, ₽", taxes.Plain.Format(rates.BaseUtilFee)},
+	})
+}
+
+func runTranslate(args []string, stdout, stderr io.Writer) error {
+	fs, format := newFlagSet("translate", stderr)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errors.New("translate requires text")
+	}
+
+	text := strings.Join(fs.Args(), " ")
+	result := struct {
+		Source      string `json:"source"`
+		Translation string `json:"translation"`
+	}{text, translations.Translate(text)}
+
+	if *format == "table" {
+		_, err := fmt.Fprintln(stdout, result.Translation)
+		return err
+	}
+	return write(stdout, *format, result, []row{
+		{"source", result.Source},
+		{"translation", result.Translation},
+	})
+}
+
+// loadCosts loads custom costs config and checks that the profile exists
+func loadCosts(path, profile string) error {
+	if path != "" {
+		if err := taxes.LoadCostProfiles(path); err != nil {
+			return err
+		}
+	}
+	if _, ok := taxes.GetCostProfile(profile); !ok {
+		return fmt.Errorf("unknown profile %q", profile)
+	}
+	return nil
+}
+
+func writeResult(w io.Writer, format string, result taxes.Result) error {
+	carInfo := result.Car
+	rows := []row{
+		{"Название", carInfo.FullName},
+		{"Год", carInfo.Year},
+		{"Пробег", carInfo.Mileage.Format("км")},
+		{"Цена, ¥", taxes.Plain.Format(carInfo.Price)},
+		{"Двигатель, см³", strconv.Itoa(carInfo.EngineSize)},
+		{"Мощность, kW", strconv.Itoa(carInfo.Power)},
+		{"Мощность, л.с.", fmt.Sprintf("%.1f", parser.KwToHp(carInfo.Power))},
+		{"Привод", carInfo.Drive},
+		{"Топливо", carInfo.FuelType},
+		{"Категория", result.TaxBand},
+	}
+	for _, item := range result.Items {
+		rows = append(rows, row{item.Name + ", ₽", taxes.Plain.Format(item.Amount)})
+	}
+	rows = append(rows, row{"Итого, ₽", taxes.Plain.Format(result.Total)})
+
+	return write(w, format, result, rows)
+}
+
+// write outputs data as JSON or rows as table or CSV
+func write(w io.Writer, format string, data interface{}, rows []row) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(data)
+
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"field", "value"})
+		for _, r := range rows {
+			cw.Write([]string{r.field, r.value})
+		}
+		cw.Flush()
+		return cw.Error()
+
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, r := range rows {
+			fmt.Fprintf(tw, "%s\t%s\n", r.field, r.value)
+		}
+		return tw.Flush()
+	}
+
+	return fmt.Errorf("unknown format %q", format)
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCalcCity(t *testing.T) {
+	total := func(args ...string) float64 {
+		var stdout, stderr bytes.Buffer
+		args = append([]string{"calc", "-engine", "1998", "-year", "2021", "-price", "150000", "-format", "json"}, args...)
+		if code := Run(args, &stdout, &stderr); code != 0 {
+			t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+		}
+		var result struct {
+			Total float64 `json:"total"`
+		}
+		if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+			t.Fatalf("invalid JSON output: %v", err)
+		}
+		return result.Total
+	}
+
+	// delivery from Shenzhen costs more than the default one in the default profile
+	if near, far := total(), total("-city", "深圳"); far <= near {
+		t.Errorf("expected city delivery to change the total: %v, %v", near, far)
+	}
+}
+
+func TestCalcFormats(t *testing.T) {
+	args := []string{"calc", "-engine", "1998", "-year", "2021", "-price", "150000"}
+
+	var stdout, stderr bytes.Buffer
+	if code := Run(append(args, "-format", "json"), &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	var result struct {
+		Total float64 `json:"total"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+	if result.Total <= 0 {
+		t.Errorf("expected positive total, got %v", result.Total)
+	}
+
+	stdout.Reset()
+	if code := Run(append(args, "-format", "csv"), &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	records, err := csv.NewReader(&stdout).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV output: %v", err)
+	}
+	if last := records[len(records)-1]; last[0] != "Итого, ₽" {
+		t.Errorf("expected total in the last row, got %v", last)
+	}
+
+	stdout.Reset()
+	if code := Run(args, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Пошлина") {
+		t.Errorf("table output has no duty row:\n%s", stdout.String())
+	}
+}
+
+func TestCalcValidation(t *testing.T) {
+	tests := [][]string{
+		{"calc", "-year", "2021", "-price", "150000"},
+		{"calc", "-engine", "1998", "-price", "150000"},
+		{"calc", "-engine", "1998", "-year", "2021"},
+		{"calc", "-engine", "1998", "-year", "2021", "-price", "150000", "-profile", "mars"},
+		{"calc", "-engine", "1998", "-year", "2021", "-price", "150000", "-format", "xml"},
+	}
+
+	for _, args := range tests {
+		var stdout, stderr bytes.Buffer
+		if code := Run(args, &stdout, &stderr); code == 0 {
+			t.Errorf("%v: expected non-zero exit code", args)
+		}
+	}
+}
+
+func TestRates(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := Run([]string{"rates", "-format", "json"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	var rates map[string]float64
+	if err := json.Unmarshal(stdout.Bytes(), &rates); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+	for _, key := range []string{"cny_rub", "eur_rub", "kzt_rub", "kgs_rub", "byn_rub"} {
+		if rates[key] <= 0 {
+			t.Errorf("expected positive %s, got %v", key, rates[key])
+		}
+	}
+
+	stdout.Reset()
+	if code := Run([]string{"rates"}, &stdout, &stderr); code != 0 || !strings.Contains(stdout.String(), "BYN → RUB") {
+		t.Errorf("table output has no BYN rate:\n%s", stdout.String())
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 )
 
@@ -54,3 +55,18 @@ func GetEnv(key string) string {
 	}
 	return ""
 }
+
+// GetEnvInt returns the integer value of the key or defaultValue if it is not set or invalid
+func GetEnvInt(key string, defaultValue int) int {
+	value := GetEnv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	intValue, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Invalid value of %s: %s, using %d", key, value, defaultValue)
+		return defaultValue
+	}
+	return intValue
+}
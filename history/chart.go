@@ -0,0 +1,102 @@
+package history
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+const (
+	chartWidth  = 8 * vg.Inch
+	chartHeight = 4 * vg.Inch
+)
+
+var (
+	colorListing = color.RGBA{200, 30, 30, 255}
+	colorSpec    = color.RGBA{120, 120, 120, 160}
+)
+
+// ChartLabels are texts of the chart in the language of the user
+type ChartLabels struct {
+	Title   string
+	Listing string // подпись цены объявления
+	Spec    string // подпись других объявлений комплектации
+	Price   string // подпись оси цены
+}
+
+// yuanTicks labels prices as whole numbers instead of the exponent notation
+type yuanTicks struct{}
+
+func (yuanTicks) Ticks(min, max float64) []plot.Tick {
+	ticks := plot.DefaultTicks{}.Ticks(min, max)
+	for i := range ticks {
+		if ticks[i].Label != "" {
+			ticks[i].Label = fmt.Sprintf("%.0f", ticks[i].Value)
+		}
+	}
+	return ticks
+}
+
+// Chart draws prices of the listing over time as a PNG image.
+// Other listings of the spec are drawn as points for comparison.
+func Chart(listing, spec []Snapshot, labels ChartLabels) ([]byte, error) {
+	if len(listing) == 0 {
+		return nil, fmt.Errorf("no snapshots to draw")
+	}
+
+	p := plot.New()
+	p.Title.Text = labels.Title
+	p.Y.Label.Text = labels.Price
+	p.X.Tick.Marker = plot.TimeTicks{Format: "2006-01-02"}
+	p.Y.Tick.Marker = yuanTicks{}
+	p.Legend.Top = true
+	p.Add(plotter.NewGrid())
+
+	carID := listing[0].Car.CarId
+	var others plotter.XYs
+	for _, s := range spec {
+		if s.Car.CarId != carID && s.Car.Price > 0 {
+			others = append(others, plotter.XY{X: float64(s.At.Unix()), Y: s.Car.Price})
+		}
+	}
+	if len(others) > 0 {
+		scatter, err := plotter.NewScatter(others)
+		if err != nil {
+			return nil, fmt.Errorf("error while drawing chart: %v", err)
+		}
+		scatter.GlyphStyle.Color = colorSpec
+		scatter.GlyphStyle.Shape = draw.CircleGlyph{}
+		p.Add(scatter)
+		p.Legend.Add(labels.Spec, scatter)
+	}
+
+	prices := make(plotter.XYs, len(listing))
+	for i, s := range listing {
+		prices[i] = plotter.XY{X: float64(s.At.Unix()), Y: s.Car.Price}
+	}
+	line, points, err := plotter.NewLinePoints(prices)
+	if err != nil {
+		return nil, fmt.Errorf("error while drawing chart: %v", err)
+	}
+	line.Color = colorListing
+	line.Width = vg.Points(2)
+	points.Color = colorListing
+	points.Shape = draw.CircleGlyph{}
+	p.Add(line, points)
+	p.Legend.Add(labels.Listing, line, points)
+
+	w, err := p.WriterTo(chartWidth, chartHeight, "png")
+	if err != nil {
+		return nil, fmt.Errorf("error while rendering chart: %v", err)
+	}
+	var buf bytes.Buffer
+	if _, err := w.WriteTo(&buf); err != nil {
+		return nil, fmt.Errorf("error while encoding chart: %v", err)
+	}
+	return buf.Bytes(), nil
+}
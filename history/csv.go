@@ -0,0 +1,57 @@
+package history
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"mashinki/parser"
+	"strconv"
+	"time"
+)
+
+var csvHeader = []string{"time", "car_id", "spec_id", "name", "year", "mileage_km", "price_cny",
+	"engine_size", "power_kw", "fuel_type", "seller", "dealer_id", "city"}
+
+// WriteCSV exports snapshots, one row per snapshot
+func WriteCSV(w io.Writer, snapshots []Snapshot) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return fmt.Errorf("error while writing csv: %v", err)
+	}
+
+	for _, s := range snapshots {
+		car := s.Car
+		row := []string{
+			s.At.UTC().Format(time.RFC3339),
+			car.CarId,
+			car.SpecID,
+			car.FullName,
+			car.Year,
+			mileageKm(car.Mileage),
+			strconv.FormatFloat(car.Price, 'f', -1, 64),
+			strconv.Itoa(car.EngineSize),
+			strconv.Itoa(car.Power),
+			car.FuelType,
+			string(car.Seller.Type),
+			car.Seller.DealerID,
+			car.Seller.City,
+		}
+		if err := cw.Write(row); err != nil {
+			return fmt.Errorf("error while writing csv: %v", err)
+		}
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("error while writing csv: %v", err)
+	}
+	return nil
+}
+
+// mileageKm is the mileage in kilometers, empty if che168 has no mileage
+func mileageKm(m parser.Mileage) string {
+	if !m.Known() {
+		return ""
+	}
+	return strconv.Itoa(m.Km)
+}
@@ -0,0 +1,124 @@
+// Package history keeps snapshots of looked-up listings to follow their prices
+package history
+
+import (
+	"fmt"
+	"mashinki/parser"
+	"mashinki/storage"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	maxSnapshots = 100    // сколько последних снимков хранится для объявления
+	maxListings  = 20_000 // объявления, которые дольше всех не запрашивались, удаляются
+)
+
+// Snapshot is the listing at the moment of a lookup
+type Snapshot struct {
+	At  time.Time      `json:"at"`
+	Car parser.CarInfo `json:"car"`
+}
+
+// Store keeps snapshots by car id in a JSON file
+type Store struct {
+	file     *storage.File
+	mu       sync.Mutex
+	listings map[string][]Snapshot
+}
+
+// NewStore loads snapshots from the file
+func NewStore(file *storage.File) (*Store, error) {
+	s := &Store{file: file, listings: make(map[string][]Snapshot)}
+	if err := file.Load(&s.listings); err != nil {
+		return nil, fmt.Errorf("error while loading price history: %v", err)
+	}
+	return s, nil
+}
+
+// Record saves snapshots of the cars taken at the time
+func (s *Store) Record(at time.Time, cars ...parser.CarInfo) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, car := range cars {
+		if car.CarId == "" {
+			continue
+		}
+		// photos are not needed for the history and take most of the space
+		car.Photos = nil
+
+		snapshots := append(s.listings[car.CarId], Snapshot{At: at, Car: car})
+		if len(snapshots) > maxSnapshots {
+			snapshots = append([]Snapshot(nil), snapshots[len(snapshots)-maxSnapshots:]...)
+		}
+		s.listings[car.CarId] = snapshots
+	}
+
+	s.prune()
+	return s.file.Save(s.listings)
+}
+
+// prune drops listings not looked up for the longest time above maxListings
+func (s *Store) prune() {
+	if len(s.listings) <= maxListings {
+		return
+	}
+
+	ids := make([]string, 0, len(s.listings))
+	for id := range s.listings {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return s.lastSeen(ids[i]).Before(s.lastSeen(ids[j]))
+	})
+	for _, id := range ids[:len(ids)-maxListings] {
+		delete(s.listings, id)
+	}
+}
+
+func (s *Store) lastSeen(carID string) time.Time {
+	snapshots := s.listings[carID]
+	return snapshots[len(snapshots)-1].At
+}
+
+// Listing returns snapshots of the listing from the oldest
+func (s *Store) Listing(carID string) []Snapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshots := append([]Snapshot(nil), s.listings[carID]...)
+	sortByTime(snapshots)
+	return snapshots
+}
+
+// Spec returns snapshots of all listings of the spec from the oldest
+func (s *Store) Spec(specID string) []Snapshot {
+	if specID == "" {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var snapshots []Snapshot
+	for _, listing := range s.listings {
+		for _, snapshot := range listing {
+			if snapshot.Car.SpecID == specID {
+				snapshots = append(snapshots, snapshot)
+			}
+		}
+	}
+	sortByTime(snapshots)
+	return snapshots
+}
+
+func sortByTime(snapshots []Snapshot) {
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		if !snapshots[i].At.Equal(snapshots[j].At) {
+			return snapshots[i].At.Before(snapshots[j].At)
+		}
+		return snapshots[i].Car.CarId < snapshots[j].Car.CarId
+	})
+}
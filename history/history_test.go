@@ -0,0 +1,118 @@
+package history
+
+import (
+	"bytes"
+	"encoding/csv"
+	"mashinki/parser"
+	"mashinki/storage"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStore(t *testing.T) {
+	file := storage.Open(t.TempDir(), "history.json")
+	store, err := NewStore(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	car := parser.CarInfo{CarId: "1", SpecID: "42", Price: 150_000, Photos: []string{"https://x/1.jpg"}}
+	other := parser.CarInfo{CarId: "2", SpecID: "42", Price: 140_000}
+	if err := store.Record(start, car, other, parser.CarInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	car.Price = 145_000
+	if err := store.Record(start.Add(24*time.Hour), car); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Record(start, parser.CarInfo{CarId: "3", SpecID: "7"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// everything is kept after restart
+	store, err = NewStore(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listing := store.Listing("1")
+	if len(listing) != 2 || listing[0].Car.Price != 150_000 || listing[1].Car.Price != 145_000 {
+		t.Fatalf("unexpected listing history %+v", listing)
+	}
+	if listing[0].Car.Photos != nil {
+		t.Errorf("photos should not be kept")
+	}
+	if spec := store.Spec("42"); len(spec) != 3 || spec[0].Car.CarId != "1" || spec[1].Car.CarId != "2" {
+		t.Errorf("unexpected spec history %+v", spec)
+	}
+	if len(store.Spec("")) != 0 || len(store.Listing("404")) != 0 {
+		t.Errorf("unknown spec and listing should have no history")
+	}
+
+	for i := 0; i < maxSnapshots+5; i++ {
+		store.Record(start.Add(time.Duration(i)*time.Minute), parser.CarInfo{CarId: "4"})
+	}
+	if n := len(store.Listing("4")); n != maxSnapshots {
+		t.Errorf("expected %d snapshots, got %d", maxSnapshots, n)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	snapshots := []Snapshot{{
+		At: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC),
+		Car: parser.CarInfo{CarId: "1", SpecID: "42", FullName: "Audi A4L, 2.0T", Year: "2021-05", Price: 150_000,
+			Seller: parser.Seller{Type: parser.SellerDealer, DealerID: "10", City: "北京"}},
+	}}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, snapshots); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || len(rows[1]) != len(csvHeader) {
+		t.Fatalf("unexpected rows %v", rows)
+	}
+	if got := strings.Join(rows[1][:7], "|"); got != "2025-05-01T12:00:00Z|1|42|Audi A4L, 2.0T|2021-05||150000" {
+		t.Errorf("unexpected row %q", got)
+	}
+	if rows[1][12] != "北京" {
+		t.Errorf("unexpected city %q", rows[1][12])
+	}
+}
+
+func TestChart(t *testing.T) {
+	start := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	listing := []Snapshot{
+		{At: start, Car: parser.CarInfo{CarId: "1", Price: 150_000}},
+		{At: start.Add(48 * time.Hour), Car: parser.CarInfo{CarId: "1", Price: 145_000}},
+	}
+	spec := append([]Snapshot{{At: start.Add(24 * time.Hour), Car: parser.CarInfo{CarId: "2", Price: 140_000}}}, listing...)
+
+	labels := ChartLabels{Title: "Audi A4L", Listing: "Это объявление", Spec: "Та же комплектация", Price: "¥"}
+	tests := []struct {
+		name    string
+		listing []Snapshot
+		spec    []Snapshot
+	}{
+		{"with spec", listing, spec},
+		{"single lookup", listing[:1], nil},
+	}
+	for _, tt := range tests {
+		img, err := Chart(tt.listing, tt.spec, labels)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !bytes.HasPrefix(img, []byte("\x89PNG")) {
+			t.Errorf("%s: chart is not a png", tt.name)
+		}
+	}
+
+	if _, err := Chart(nil, spec, ChartLabels{}); err == nil {
+		t.Errorf("expected error without snapshots")
+	}
+}
@@ -0,0 +1,108 @@
+// Package i18n holds translations of the bot interface and calculation results
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Lang is a two-letter language code
+type Lang string
+
+const (
+	Russian Lang = "ru"
+	English Lang = "en"
+	Kazakh  Lang = "kk"
+	Kyrgyz  Lang = "ky"
+
+	Default = Russian
+)
+
+// Languages lists all supported languages in the order they are offered to users
+var Languages = []Lang{Russian, English, Kazakh, Kyrgyz}
+
+//go:embed locales/*.json
+var locales embed.FS
+
+// catalogs maps language to its messages
+var catalogs = map[Lang]map[string]string{}
+
+func init() {
+	for _, lang := range Languages {
+		data, err := locales.ReadFile("locales/" + string(lang) + ".json")
+		if err != nil {
+			panic("error while reading locale " + string(lang) + ": " + err.Error())
+		}
+
+		messages := map[string]string{}
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic("error while parsing locale " + string(lang) + ": " + err.Error())
+		}
+		catalogs[lang] = messages
+	}
+}
+
+// Parse returns the supported language for a code like "en" or "en-US",
+// falling back to the default language
+func Parse(code string) Lang {
+	if lang, ok := Lookup(code); ok {
+		return lang
+	}
+	return Default
+}
+
+// Lookup returns the supported language for a code like "en" or "en-US"
+func Lookup(code string) (Lang, bool) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if idx := strings.IndexAny(code, "-_"); idx != -1 {
+		code = code[:idx]
+	}
+
+	for _, lang := range Languages {
+		if string(lang) == code {
+			return lang, true
+		}
+	}
+	return "", false
+}
+
+// T returns the message in the language formatted with args.
+// Missing messages fall back to the default language and then to the key itself.
+func T(lang Lang, key string, args ...interface{}) string {
+	msg, ok := catalogs[lang][key]
+	if !ok {
+		msg, ok = catalogs[Default][key]
+	}
+	if !ok {
+		return key
+	}
+
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// Matches tells whether the text is the message in any language,
+// used to recognize keyboard buttons
+func Matches(text, key string) bool {
+	for _, lang := range Languages {
+		if msg, ok := catalogs[lang][key]; ok && msg == text {
+			return true
+		}
+	}
+	return false
+}
+
+// Name returns the native name of the language
+func (l Lang) Name() string {
+	return T(l, "language.name")
+}
+
+// TranslationTarget returns the language listings are machine translated to.
+// Languages not loaded in LibreTranslate get Russian translations.
+func (l Lang) TranslationTarget() string {
+	return T(l, "translate_to")
+}
@@ -0,0 +1,71 @@
+package i18n
+
+import (
+	"regexp"
+	"testing"
+)
+
+var verbRegexp = regexp.MustCompile(`%[%a-z]`)
+
+func TestCatalogsComplete(t *testing.T) {
+	for _, lang := range Languages[1:] {
+		for key, msg := range catalogs[Default] {
+			translated, ok := catalogs[lang][key]
+			if !ok {
+				t.Errorf("%s: missing %q", lang, key)
+				continue
+			}
+
+			want := verbRegexp.FindAllString(msg, -1)
+			got := verbRegexp.FindAllString(translated, -1)
+			if len(want) != len(got) {
+				t.Errorf("%s: %q has verbs %v, want %v", lang, key, got, want)
+			}
+		}
+		for key := range catalogs[lang] {
+			if _, ok := catalogs[Default][key]; !ok {
+				t.Errorf("%s: unknown key %q", lang, key)
+			}
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := map[string]Lang{
+		"en":    English,
+		"en-US": English,
+		"KK":    Kazakh,
+		"ky":    Kyrgyz,
+		"ru":    Russian,
+		"de":    Default,
+		"":      Default,
+	}
+	for code, want := range tests {
+		if got := Parse(code); got != want {
+			t.Errorf("Parse(%q) = %q, want %q", code, got, want)
+		}
+	}
+}
+
+func TestT(t *testing.T) {
+	if got := T(English, "bulk.progress", 1, 2); got != "🔄 Processed 1 of 2" {
+		t.Errorf("unexpected message: %q", got)
+	}
+	if got := T(Lang("de"), "compare.total"); got != "Итого" {
+		t.Errorf("unknown language should fall back to Russian, got %q", got)
+	}
+	if got := T(English, "no.such.key"); got != "no.such.key" {
+		t.Errorf("unknown key should be returned as is, got %q", got)
+	}
+}
+
+func TestMatches(t *testing.T) {
+	for _, lang := range Languages {
+		if !Matches(T(lang, "btn.find_car"), "btn.find_car") {
+			t.Errorf("%s: button is not recognized", lang)
+		}
+	}
+	if Matches("hello", "btn.find_car") {
+		t.Error("arbitrary text should not match")
+	}
+}
@@ -37,16 +37,64 @@ func NewFLogger(filename string) (*FLogger, error) {
 	return &FLogger{logFile: logFile}, nil
 }
 
+// Logger falls back to stderr if its file could not be opened or is already closed
 func (l *FLogger) LogError(err error) {
+	if l == nil {
+		log.Println(err)
+		return
+	}
 	l.mu.Lock()
 	defer l.mu.Unlock()
-	log.SetOutput(l.logFile)
+	if l.logFile == nil {
+		log.SetOutput(os.Stderr)
+	} else {
+		log.SetOutput(l.logFile)
+	}
 	log.Println(err)
 }
 
 func (l *FLogger) LogErrorF(format string, args ...interface{}) {
+	if l == nil {
+		log.Printf(format, args...)
+		return
+	}
 	l.mu.Lock()
 	defer l.mu.Unlock()
-	log.SetOutput(l.logFile)
+	if l.logFile == nil {
+		log.SetOutput(os.Stderr)
+	} else {
+		log.SetOutput(l.logFile)
+	}
 	log.Printf(format, args...)
 }
+
+// Close flushes the log file to disk and closes it
+func (l *FLogger) Close() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.logFile == nil {
+		return nil
+	}
+
+	syncErr := l.logFile.Sync()
+	closeErr := l.logFile.Close()
+	l.logFile = nil
+	log.SetOutput(os.Stderr)
+
+	if syncErr != nil {
+		return syncErr
+	}
+	return closeErr
+}
+
+// CloseAll closes default loggers on shutdown
+func CloseAll() {
+	for _, l := range []*FLogger{DefaultLogger, TranslationsLogger} {
+		if err := l.Close(); err != nil {
+			log.Printf("Failed to close log file: %v", err)
+		}
+	}
+}
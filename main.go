@@ -3,15 +3,29 @@ package main
 import (
 	"errors"
 	"log"
+	"mashinki/api"
+	"mashinki/cli"
 	envhandler "mashinki/envHandler"
 	"mashinki/logging"
+	"mashinki/taxes"
 	"mashinki/tgBot"
+	"mashinki/translations"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 )
 
 func main() {
+	// Running CLI command if one is given, the bot is the default mode
+	if len(os.Args) > 1 && os.Args[1] != "bot" {
+		os.Exit(cli.Run(os.Args[1:], os.Stdout, os.Stderr))
+	}
+
+	runBot()
+}
+
+func runBot() {
 	// Checking if bot token is available
 	botToken := envhandler.GetEnv("TG_TOKEN")
 	if botToken == "" {
@@ -19,6 +33,22 @@ func main() {
 		return
 	}
 
+	// Loading delivery and registration costs if custom config is set
+	if costsPath := envhandler.GetEnv("COSTS_CONFIG"); costsPath != "" {
+		if err := taxes.LoadCostProfiles(costsPath); err != nil {
+			logging.DefaultLogger.LogErrorF("Failed to load costs config: %v", err)
+			return
+		}
+	}
+
+	// Loading glossary of fixed translations if set
+	if glossaryPath := envhandler.GetEnv("GLOSSARY"); glossaryPath != "" {
+		if err := translations.LoadGlossary(glossaryPath); err != nil {
+			logging.DefaultLogger.LogErrorF("Failed to load glossary: %v", err)
+			return
+		}
+	}
+
 	log.Println("Starting bot...")
 	bot, err := tgBot.StartBot()
 	if err != nil {
@@ -26,6 +56,17 @@ func main() {
 		return
 	}
 
+	// Starting HTTP API alongside the bot if address is set
+	var apiServer *api.Server
+	if apiAddr := envhandler.GetEnv("API_ADDR"); apiAddr != "" {
+		apiServer, err = api.StartServer(apiAddr, strings.Split(envhandler.GetEnv("API_KEYS"), ","))
+		if err != nil {
+			logging.DefaultLogger.LogErrorF("Failed to start API server: %v", err)
+			bot.Stop()
+			return
+		}
+	}
+
 	// graceful shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGTERM, syscall.SIGINT)
@@ -34,4 +75,9 @@ func main() {
 	<-quit
 	log.Println("Shutting down bot...")
 	bot.Stop()
+	if apiServer != nil {
+		apiServer.Stop()
+	}
+	logging.CloseAll()
+	log.Println("Bot stopped")
 }
@@ -0,0 +1,165 @@
+// Package market compares the listing price with other che168 listings of the same spec
+package market
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mashinki/parser"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	MinSamples = 5 // меньше объявлений недостаточно для сравнения
+	maxPages   = 3 // сколько страниц поиска по комплектации просматривается
+	yearRange  = 1 // сравниваются машины +-1 год от года выпуска
+)
+
+var (
+	// ErrNoSpec means that the spec of the car is unknown
+	ErrNoSpec = errors.New("car has no spec id")
+	// ErrNotEnoughData means that there are less than MinSamples comparable listings
+	ErrNotEnoughData = errors.New("not enough listings of the spec")
+)
+
+// Verdict tells how the price compares to the market
+type Verdict string
+
+const (
+	VerdictCheap     Verdict = "cheap"     // дешевле четверти рынка
+	VerdictFair      Verdict = "fair"      // в середине рынка
+	VerdictExpensive Verdict = "expensive" // дороже трех четвертей рынка
+)
+
+// Analysis is the price of the car compared to comparable listings
+type Analysis struct {
+	SpecID   string `json:"spec_id"`
+	YearFrom int    `json:"year_from"` // 0 если год не учитывается
+	YearTo   int    `json:"year_to"`
+	Stats    Stats  `json:"stats"`
+
+	Price      float64 `json:"price"`        // в юанях
+	PricePerKm float64 `json:"price_per_km"` // 0 если пробег неизвестен
+	Percentile float64 `json:"percentile"`   // доля объявлений дешевле, %
+	Diff       float64 `json:"diff"`         // отклонение от медианы, доля
+	Verdict    Verdict `json:"verdict"`
+}
+
+// Analyzer collects listings of the spec and compares prices
+type Analyzer struct {
+	store    *Store
+	Listings func(q parser.SearchQuery, page int) ([]parser.Listing, error)
+}
+
+// NewAnalyzer creates an analyzer requesting che168 and keeping samples in the store
+func NewAnalyzer(store *Store) *Analyzer {
+	return &Analyzer{store: store, Listings: parser.SearchListings}
+}
+
+// Analyze collects fresh listings of the car's spec and compares the car with the listings
+// of the same spec and close years, including those stored by previous analyses.
+func (a *Analyzer) Analyze(ctx context.Context, car parser.CarInfo) (Analysis, error) {
+	if car.SpecID == "" {
+		return Analysis{}, ErrNoSpec
+	}
+
+	collectErr := a.collect(ctx, car)
+	if ctx.Err() != nil {
+		return Analysis{}, ctx.Err()
+	}
+
+	analysis, err := Compare(car, a.store.Samples(car.SpecID))
+	if errors.Is(err, ErrNotEnoughData) && collectErr != nil {
+		return Analysis{}, collectErr
+	}
+	return analysis, err
+}
+
+// collect saves listings of the spec from che168 search pages and the car itself
+func (a *Analyzer) collect(ctx context.Context, car parser.CarInfo) error {
+	now := time.Now()
+	samples := []Sample{{
+		CarID:   car.CarId,
+		Year:    carYear(car.Year),
+		Price:   car.Price,
+		Mileage: carMileage(car.Mileage),
+		SeenAt:  now,
+	}}
+
+	var err error
+	for page := 1; page <= maxPages && ctx.Err() == nil; page++ {
+		listings, listErr := a.Listings(parser.SearchQuery{SpecID: car.SpecID}, page)
+		if listErr != nil {
+			err = fmt.Errorf("error while getting listings of spec %s: %v", car.SpecID, listErr)
+			break
+		}
+		if len(listings) == 0 {
+			break
+		}
+		for _, l := range listings {
+			samples = append(samples, Sample{CarID: l.CarId, Year: l.Year, Price: l.Price, Mileage: l.Mileage, SeenAt: now})
+		}
+	}
+
+	if saveErr := a.store.Add(car.SpecID, samples, now); saveErr != nil {
+		return fmt.Errorf("error while saving market samples: %v", saveErr)
+	}
+	return err
+}
+
+// Compare compares the car with other samples of close years
+func Compare(car parser.CarInfo, samples []Sample) (Analysis, error) {
+	analysis := Analysis{SpecID: car.SpecID, Price: car.Price}
+	if year := carYear(car.Year); year > 0 {
+		analysis.YearFrom, analysis.YearTo = year-yearRange, year+yearRange
+	}
+
+	var comparable []Sample
+	var prices []float64
+	for _, sample := range samples {
+		if sample.CarID == car.CarId {
+			continue
+		}
+		if analysis.YearFrom > 0 && (sample.Year < analysis.YearFrom || sample.Year > analysis.YearTo) {
+			continue
+		}
+		comparable = append(comparable, sample)
+		prices = append(prices, sample.Price)
+	}
+	if len(comparable) < MinSamples {
+		return Analysis{}, ErrNotEnoughData
+	}
+
+	analysis.Stats = Compute(comparable)
+	analysis.Percentile = percentile(prices, car.Price)
+	analysis.Diff = (car.Price - analysis.Stats.Median) / analysis.Stats.Median
+	if mileage := carMileage(car.Mileage); mileage > 0 {
+		analysis.PricePerKm = car.Price / mileage
+	}
+
+	switch {
+	case car.Price < analysis.Stats.P25:
+		analysis.Verdict = VerdictCheap
+	case car.Price > analysis.Stats.P75:
+		analysis.Verdict = VerdictExpensive
+	default:
+		analysis.Verdict = VerdictFair
+	}
+	return analysis, nil
+}
+
+// carYear returns the year of "2021-05", 0 if the car is not registered
+func carYear(year string) int {
+	y, _ := strconv.Atoi(strings.SplitN(year, "-", 2)[0])
+	return y
+}
+
+// carMileage returns kilometers, 0 if the mileage is unknown
+func carMileage(m parser.Mileage) float64 {
+	if !m.Known() {
+		return 0
+	}
+	return float64(m.Km)
+}
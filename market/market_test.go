@@ -0,0 +1,147 @@
+package market
+
+import (
+	"context"
+	"errors"
+	"mashinki/parser"
+	"mashinki/storage"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestCompute(t *testing.T) {
+	var samples []Sample
+	for i, price := range []float64{100_000, 120_000, 110_000, 130_000, 140_000} {
+		samples = append(samples, Sample{CarID: string(rune('a' + i)), Price: price, Mileage: 10_000})
+	}
+
+	s := Compute(samples)
+	if s.Count != 5 || s.Median != 120_000 || s.P25 != 110_000 || s.P75 != 130_000 || s.Min != 100_000 || s.Max != 140_000 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+	if s.MedianPerKm != 12 {
+		t.Errorf("expected 12 yuan per km, got %v", s.MedianPerKm)
+	}
+	if p := percentile([]float64{1, 2, 3, 4}, 3); p != 62.5 {
+		t.Errorf("expected 62.5 percentile, got %v", p)
+	}
+	if Compute(nil).Count != 0 {
+		t.Errorf("expected empty stats")
+	}
+}
+
+func TestCompare(t *testing.T) {
+	samples := []Sample{
+		{CarID: "1", Year: 2020, Price: 100_000},
+		{CarID: "2", Year: 2021, Price: 110_000},
+		{CarID: "3", Year: 2021, Price: 120_000},
+		{CarID: "4", Year: 2022, Price: 130_000},
+		{CarID: "5", Year: 2022, Price: 140_000},
+		{CarID: "old", Year: 2015, Price: 50_000},
+		{CarID: "car", Year: 2021, Price: 1},
+	}
+	car := parser.CarInfo{CarId: "car", SpecID: "1", Year: "2021-05", Price: 100_000, Mileage: parser.Mileage{Km: 20_000, Unit: parser.UnitKm, Confidence: parser.ConfidenceExact}}
+
+	a, err := Compare(car, samples)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Stats.Count != 5 || a.YearFrom != 2020 || a.YearTo != 2022 {
+		t.Errorf("the car itself and other years should be skipped: %+v", a)
+	}
+	if a.Verdict != VerdictCheap || a.Percentile != 10 || math.Abs(a.Diff+1.0/6) > 1e-9 || a.PricePerKm != 5 {
+		t.Errorf("unexpected analysis %+v", a)
+	}
+
+	car.Price = 150_000
+	if a, _ = Compare(car, samples); a.Verdict != VerdictExpensive {
+		t.Errorf("expected expensive, got %s", a.Verdict)
+	}
+	car.Price = 120_000
+	if a, _ = Compare(car, samples); a.Verdict != VerdictFair {
+		t.Errorf("expected fair, got %s", a.Verdict)
+	}
+
+	if _, err := Compare(car, samples[:4]); !errors.Is(err, ErrNotEnoughData) {
+		t.Errorf("expected ErrNotEnoughData, got %v", err)
+	}
+}
+
+func TestAnalyzer(t *testing.T) {
+	file := storage.Open(t.TempDir(), "market.json")
+	store, err := NewStore(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pages := map[int][]parser.Listing{
+		1: {{CarId: "1", Year: 2021, Price: 100_000}, {CarId: "2", Year: 2021, Price: 110_000}, {CarId: "3", Year: 2021, Price: 120_000}},
+		2: {{CarId: "4", Year: 2021, Price: 130_000}},
+	}
+	var requested []string
+	a := NewAnalyzer(store)
+	a.Listings = func(q parser.SearchQuery, page int) ([]parser.Listing, error) {
+		requested = append(requested, q.SpecID)
+		return pages[page], nil
+	}
+
+	car := parser.CarInfo{CarId: "car", SpecID: "42", Year: "2021-01", Price: 115_000}
+	if _, err := a.Analyze(context.Background(), car); !errors.Is(err, ErrNotEnoughData) {
+		t.Fatalf("expected ErrNotEnoughData, got %v", err)
+	}
+	if len(requested) != 3 || requested[0] != "42" {
+		t.Errorf("unexpected requests %v", requested)
+	}
+
+	// samples build up over time
+	pages[1] = []parser.Listing{{CarId: "5", Year: 2021, Price: 140_000}}
+	a.Listings = func(q parser.SearchQuery, page int) ([]parser.Listing, error) {
+		if page > 1 {
+			return nil, errors.New("blocked")
+		}
+		return pages[page], nil
+	}
+	store, _ = NewStore(file)
+	a.store = store
+	analysis, err := a.Analyze(context.Background(), car)
+	if err != nil || analysis.Stats.Count != 5 || analysis.Verdict != VerdictFair {
+		t.Errorf("unexpected analysis %+v, %v", analysis, err)
+	}
+
+	if _, err := a.Analyze(context.Background(), parser.CarInfo{}); !errors.Is(err, ErrNoSpec) {
+		t.Errorf("expected ErrNoSpec, got %v", err)
+	}
+}
+
+func TestStoreLimits(t *testing.T) {
+	store, err := NewStore(storage.Open(t.TempDir(), "market.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	old := []Sample{{CarID: "old", Price: 1, SeenAt: now.Add(-maxAge - time.Hour)}}
+	if err := store.Add("1", old, now.Add(-maxAge-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	var samples []Sample
+	for i := 0; i < maxSamples+10; i++ {
+		samples = append(samples, Sample{CarID: string(rune(0x4e00 + i)), Price: 1, SeenAt: now})
+	}
+	samples = append(samples, Sample{CarID: "free", SeenAt: now})
+	if err := store.Add("1", samples, now); err != nil {
+		t.Fatal(err)
+	}
+
+	kept := store.Samples("1")
+	if len(kept) != maxSamples {
+		t.Errorf("expected %d samples, got %d", maxSamples, len(kept))
+	}
+	for _, s := range kept {
+		if s.CarID == "old" || s.CarID == "free" {
+			t.Errorf("sample %q should be dropped", s.CarID)
+		}
+	}
+}
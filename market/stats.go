@@ -0,0 +1,78 @@
+package market
+
+import "sort"
+
+// Stats are prices of comparable listings
+type Stats struct {
+	Count  int     `json:"count"`
+	Median float64 `json:"median"` // в юанях
+	P25    float64 `json:"p25"`
+	P75    float64 `json:"p75"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+
+	// медиана цены за километр пробега, 0 если у машин нет пробега
+	MedianPerKm float64 `json:"median_per_km"`
+}
+
+// Compute counts statistics of the samples
+func Compute(samples []Sample) Stats {
+	if len(samples) == 0 {
+		return Stats{}
+	}
+
+	prices := make([]float64, 0, len(samples))
+	var perKm []float64
+	for _, sample := range samples {
+		prices = append(prices, sample.Price)
+		if sample.Mileage > 0 {
+			perKm = append(perKm, sample.Price/sample.Mileage)
+		}
+	}
+	sort.Float64s(prices)
+	sort.Float64s(perKm)
+
+	return Stats{
+		Count:       len(prices),
+		Median:      quantile(prices, 0.5),
+		P25:         quantile(prices, 0.25),
+		P75:         quantile(prices, 0.75),
+		Min:         prices[0],
+		Max:         prices[len(prices)-1],
+		MedianPerKm: quantile(perKm, 0.5),
+	}
+}
+
+// quantile interpolates between the nearest values of the sorted slice
+func quantile(sorted []float64, q float64) float64 {
+	switch len(sorted) {
+	case 0:
+		return 0
+	case 1:
+		return sorted[0]
+	}
+
+	pos := q * float64(len(sorted)-1)
+	i := int(pos)
+	if i >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[i] + (sorted[i+1]-sorted[i])*(pos-float64(i))
+}
+
+// percentile is the share of prices lower than the price, ties count as a half
+func percentile(prices []float64, price float64) float64 {
+	if len(prices) == 0 {
+		return 0
+	}
+	var below float64
+	for _, p := range prices {
+		switch {
+		case p < price:
+			below++
+		case p == price:
+			below += 0.5
+		}
+	}
+	return below / float64(len(prices)) * 100
+}
@@ -0,0 +1,82 @@
+package market
+
+import (
+	"fmt"
+	"mashinki/storage"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	maxSamples = 300                  // сколько последних объявлений хранится для комплектации
+	maxAge     = 180 * 24 * time.Hour // более старые цены уже не отражают рынок
+)
+
+// Sample is a price of one listing of the spec
+type Sample struct {
+	CarID   string    `json:"car_id"`
+	Year    int       `json:"year"`    // 0 если машина не ставилась на учет
+	Price   float64   `json:"price"`   // в юанях
+	Mileage float64   `json:"mileage"` // в километрах
+	SeenAt  time.Time `json:"seen_at"`
+}
+
+// Store keeps samples by spec id in a JSON file, so statistics build up over time
+type Store struct {
+	file    *storage.File
+	mu      sync.Mutex
+	samples map[string][]Sample
+}
+
+// NewStore loads samples from the file
+func NewStore(file *storage.File) (*Store, error) {
+	s := &Store{file: file, samples: make(map[string][]Sample)}
+	if err := file.Load(&s.samples); err != nil {
+		return nil, fmt.Errorf("error while loading market samples: %v", err)
+	}
+	return s, nil
+}
+
+// Add saves samples of the spec. A listing seen again replaces its old sample,
+// samples older than maxAge are dropped.
+func (s *Store) Add(specID string, samples []Sample, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	byCar := make(map[string]Sample)
+	for _, sample := range s.samples[specID] {
+		if now.Sub(sample.SeenAt) <= maxAge {
+			byCar[sample.CarID] = sample
+		}
+	}
+	for _, sample := range samples {
+		if sample.CarID != "" && sample.Price > 0 {
+			byCar[sample.CarID] = sample
+		}
+	}
+
+	kept := make([]Sample, 0, len(byCar))
+	for _, sample := range byCar {
+		kept = append(kept, sample)
+	}
+	sort.Slice(kept, func(i, j int) bool {
+		if !kept[i].SeenAt.Equal(kept[j].SeenAt) {
+			return kept[i].SeenAt.After(kept[j].SeenAt)
+		}
+		return kept[i].CarID < kept[j].CarID
+	})
+	if len(kept) > maxSamples {
+		kept = kept[:maxSamples]
+	}
+
+	s.samples[specID] = kept
+	return s.file.Save(s.samples)
+}
+
+// Samples returns stored samples of the spec, the newest first
+func (s *Store) Samples(specID string) []Sample {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Sample(nil), s.samples[specID]...)
+}
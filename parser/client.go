@@ -3,8 +3,11 @@ package parser
 import (
 	"fmt"
 	"io"
+	"mime"
+	"net"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
 	envhandler "mashinki/envHandler"
@@ -13,19 +16,40 @@ import (
 	"golang.org/x/text/transform"
 )
 
-// makeRequest makes http request
-//
-// mode: 0 - just get response
-//
-// mode: 1 - get response into GBK encoding
-func makeRequest(targetUrl string, mode int) (string, error) {
+// CheckHealth checks that che168 is reachable through the proxy
+func CheckHealth() error {
+	_, err := makeRequest("https://www.che168.com", 0)
+	return err
+}
+
+// CheckProxy checks that the proxy accepts connections
+func CheckProxy() error {
+	proxyURL, err := url.Parse(envhandler.GetEnv("PROXY"))
+	if err != nil {
+		return fmt.Errorf("error while parsing proxy url: %v", err)
+	}
+	if proxyURL.Host == "" {
+		return fmt.Errorf("proxy is not set")
+	}
+
+	conn, err := net.DialTimeout("tcp", proxyURL.Host, 5*time.Second)
+	if err != nil {
+		return fmt.Errorf("error while connecting to proxy: %v", err)
+	}
+	return conn.Close()
+}
+
+// maxPhotoSize limits the size of a downloaded photo
+const maxPhotoSize = 10 << 20
+
+// newClient creates an http client working through the proxy
+func newClient() *http.Client {
 	proxyURL, err := url.Parse(envhandler.GetEnv("PROXY"))
 	if err != nil {
 		panic("error while patsing proxy url: " + err.Error())
 	}
 
-	// initializing http client
-	client := &http.Client{
+	return &http.Client{
 		Transport: &http.Transport{
 			Proxy:             http.ProxyURL(proxyURL),
 			IdleConnTimeout:   30 * time.Second,
@@ -33,10 +57,13 @@ func makeRequest(targetUrl string, mode int) (string, error) {
 		},
 		Timeout: 15 * time.Second,
 	}
+}
 
+// get sends a GET request with headers of the mode and checks the status
+func get(targetUrl string, mode int) (*http.Response, error) {
 	req, err := http.NewRequest("GET", targetUrl, nil)
 	if err != nil {
-		return "", fmt.Errorf("error while creating request: %v", err)
+		return nil, fmt.Errorf("error while creating request: %v", err)
 	}
 
 	// Setting up headers
@@ -53,39 +80,75 @@ func makeRequest(targetUrl string, mode int) (string, error) {
 		req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36")
 		req.Header.Set("Accept", "*/*")
 		req.Header.Set("Referer", "https://www.che168.com")
+	case 2:
+		req.Header.Set("User-Agent", "Mozilla/5.0 (iPhone; CPU iPhone OS 16_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/16.0 Mobile/15E148 Safari/604.1")
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("Referer", "https://m.che168.com")
 	}
 
-	resp, err := client.Do(req)
+	resp, err := newClient().Do(req)
 	if err != nil {
-		return "", fmt.Errorf("error while sending request: %v", err)
+		return nil, fmt.Errorf("error while sending request: %v", err)
 	}
-	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("server returned status %d instead of 200 OK", resp.StatusCode)
+		resp.Body.Close()
+		return nil, fmt.Errorf("server returned status %d instead of 200 OK", resp.StatusCode)
 	}
+	return resp, nil
+}
 
-	reader := transform.NewReader(resp.Body, simplifiedchinese.GBK.NewDecoder())
-	body, err := io.ReadAll(reader)
+// decodeBody decodes the response by its charset.
+// che168 pages without a charset are in GBK, json without a charset is in UTF-8.
+func decodeBody(body io.Reader, contentType string) io.Reader {
+	mediaType, params, _ := mime.ParseMediaType(contentType)
+	switch charset := strings.ToLower(params["charset"]); {
+	case charset == "utf-8" || charset == "utf8":
+		return body
+	case charset == "" && mediaType == "application/json":
+		return body
+	case charset == "gb18030":
+		return transform.NewReader(body, simplifiedchinese.GB18030.NewDecoder())
+	default:
+		return transform.NewReader(body, simplifiedchinese.GBK.NewDecoder())
+	}
+}
+
+// makeRequest makes http request and decodes the response by its charset
+//
+// mode: 0 - desktop page
+//
+// mode: 1 - desktop api
+//
+// mode: 2 - mobile api
+func makeRequest(targetUrl string, mode int) (string, error) {
+	resp, err := get(targetUrl, mode)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(decodeBody(resp.Body, resp.Header.Get("Content-Type")))
 	if err != nil {
 		return "", fmt.Errorf("error while reading response: %v", err)
 	}
 	return string(body), nil
+}
 
-	// switch mode {
-	// case 0:
-	// 	body, err := io.ReadAll(resp.Body)
-	// 	if err != nil {
-	// 		return "", fmt.Errorf("error while reading response: %v", err)
-	// 	}
-	// 	return string(body), nil
-	// case 1:
-	// 	reader := transform.NewReader(resp.Body, simplifiedchinese.GBK.NewDecoder())
-	// 	body, err := io.ReadAll(reader)
-	// 	if err != nil {
-	// 		return "", fmt.Errorf("error while reading response: %v", err)
-	// 	}
-	// 	return string(body), nil
-	// }
-	// return "", nil
+// DownloadPhoto downloads a listing photo through the proxy
+func DownloadPhoto(photoURL string) ([]byte, error) {
+	resp, err := get(photoURL, 1)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxPhotoSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("error while reading photo: %v", err)
+	}
+	if len(data) > maxPhotoSize {
+		return nil, fmt.Errorf("photo is larger than %d bytes", maxPhotoSize)
+	}
+	return data, nil
 }
@@ -0,0 +1,183 @@
+package parser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// inspectionURL returns the inspection report (检测报告) of the listing
+const inspectionURL = "https://apiuscdt.che168.com/api/v1/car/getcarinspection?infoid=%s&callback=inspection"
+
+// repaintThreshold is the paint thickness in microns above which a part is considered repainted
+const repaintThreshold = 250
+
+// ErrNoReport means that the listing has no inspection report
+var ErrNoReport = errors.New("listing has no inspection report")
+
+// RiskCategory groups inspection checks
+type RiskCategory string
+
+const (
+	RiskAccident RiskCategory = "accident" // 事故排查
+	RiskFlood    RiskCategory = "flood"    // 泡水排查
+	RiskFire     RiskCategory = "fire"     // 火烧排查
+	RiskOther    RiskCategory = "other"
+)
+
+// InspectionReport is a structured che168 inspection report
+type InspectionReport struct {
+	CarId     string            `json:"car_id"`
+	Transfers int               `json:"transfers"` // число смен владельца (过户次数), -1 если неизвестно
+	Checks    []InspectionCheck `json:"checks"`
+	Defects   []Defect          `json:"defects"`
+	Paint     []PaintReading    `json:"paint"`
+}
+
+// InspectionCheck is one checked item of the report
+type InspectionCheck struct {
+	Category RiskCategory `json:"category"`
+	Name     string       `json:"name"`
+	Passed   bool         `json:"passed"`
+	Note     string       `json:"note,omitempty"`
+}
+
+// Defect is a damage found in a body zone
+type Defect struct {
+	Zone string `json:"zone"` // зона кузова как в отчете: 左前门
+	Kind string `json:"kind"` // вид повреждения: 划痕, 凹陷
+	Note string `json:"note,omitempty"`
+}
+
+// PaintReading is the paint thickness of a body part
+type PaintReading struct {
+	Part    string `json:"part"`
+	Microns int    `json:"microns"`
+}
+
+// RiskSummary is a condensed view of the report
+type RiskSummary struct {
+	Failed    []RiskCategory `json:"failed"` // серьезные категории с непройденными проверками
+	Checks    int            `json:"checks"`
+	Passed    int            `json:"passed"`
+	Defects   int            `json:"defects"`
+	Repainted int            `json:"repainted"` // детали с толщиной краски выше repaintThreshold
+	Transfers int            `json:"transfers"`
+	Warning   bool           `json:"warning"` // машина была в ДТП, затоплена или горела
+}
+
+// inspectionResponse is the answer of the inspection endpoint
+type inspectionResponse struct {
+	ReturnCode int    `json:"returncode"`
+	Message    string `json:"message"`
+	Result     *struct {
+		TransferCount *int `json:"transfercount"`
+		CheckItems    []struct {
+			Category string `json:"category"`
+			Name     string `json:"name"`
+			Status   int    `json:"status"` // 0 - норма, иначе найдена проблема
+			Desc     string `json:"desc"`
+		} `json:"checkitems"`
+		Defects []struct {
+			Zone string `json:"zone"`
+			Type string `json:"type"`
+			Desc string `json:"desc"`
+		} `json:"defects"`
+		Paint []struct {
+			Part      string `json:"part"`
+			Thickness int    `json:"thickness"`
+		} `json:"paint"`
+	} `json:"result"`
+}
+
+// GetInspectionReport retrieves the inspection report of the listing
+func GetInspectionReport(carID string) (InspectionReport, error) {
+	resp, err := makeRequest(fmt.Sprintf(inspectionURL, carID), 1)
+	if err != nil {
+		return InspectionReport{}, fmt.Errorf("failed to get inspection report: %v", err)
+	}
+
+	report, err := ParseInspectionReport(resp)
+	if err != nil {
+		return InspectionReport{}, err
+	}
+	report.CarId = carID
+	return report, nil
+}
+
+// ParseInspectionReport parses the answer of the inspection endpoint, JSONP or plain JSON
+func ParseInspectionReport(body string) (InspectionReport, error) {
+	body = strings.TrimSpace(body)
+	if start, end := strings.Index(body, "("), strings.LastIndex(body, ")"); !strings.HasPrefix(body, "{") && start != -1 && end > start {
+		body = body[start+1 : end]
+	}
+
+	var resp inspectionResponse
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		return InspectionReport{}, fmt.Errorf("error while parsing JSON: %v", err)
+	}
+	if resp.ReturnCode != 0 || resp.Result == nil || len(resp.Result.CheckItems) == 0 {
+		return InspectionReport{}, ErrNoReport
+	}
+
+	report := InspectionReport{Transfers: -1}
+	if resp.Result.TransferCount != nil {
+		report.Transfers = *resp.Result.TransferCount
+	}
+	for _, item := range resp.Result.CheckItems {
+		report.Checks = append(report.Checks, InspectionCheck{
+			Category: riskCategory(item.Category),
+			Name:     item.Name,
+			Passed:   item.Status == 0,
+			Note:     item.Desc,
+		})
+	}
+	for _, d := range resp.Result.Defects {
+		report.Defects = append(report.Defects, Defect{Zone: d.Zone, Kind: d.Type, Note: d.Desc})
+	}
+	for _, p := range resp.Result.Paint {
+		report.Paint = append(report.Paint, PaintReading{Part: p.Part, Microns: p.Thickness})
+	}
+	return report, nil
+}
+
+// riskCategory maps the category title of the report
+func riskCategory(title string) RiskCategory {
+	switch {
+	case strings.Contains(title, "事故"):
+		return RiskAccident
+	case strings.Contains(title, "泡水"), strings.Contains(title, "水泡"):
+		return RiskFlood
+	case strings.Contains(title, "火烧"):
+		return RiskFire
+	}
+	return RiskOther
+}
+
+// Summary condenses the report, serious categories are accident, flood and fire
+func (r InspectionReport) Summary() RiskSummary {
+	s := RiskSummary{Checks: len(r.Checks), Defects: len(r.Defects), Transfers: r.Transfers}
+
+	failed := make(map[RiskCategory]bool)
+	for _, c := range r.Checks {
+		if c.Passed {
+			s.Passed++
+		} else if c.Category != RiskOther {
+			failed[c.Category] = true
+		}
+	}
+	for _, category := range []RiskCategory{RiskAccident, RiskFlood, RiskFire} {
+		if failed[category] {
+			s.Failed = append(s.Failed, category)
+		}
+	}
+	s.Warning = len(s.Failed) > 0
+
+	for _, p := range r.Paint {
+		if p.Microns > repaintThreshold {
+			s.Repainted++
+		}
+	}
+	return s
+}
@@ -0,0 +1,69 @@
+package parser
+
+import (
+	"errors"
+	"testing"
+)
+
+const inspectionJSONP = `inspection({"returncode":0,"message":"成功","result":{
+	"transfercount":2,
+	"checkitems":[
+		{"category":"事故排查","name":"A柱","status":0},
+		{"category":"事故排查","name":"左前纵梁","status":1,"desc":"焊接"},
+		{"category":"泡水排查","name":"座椅滑轨","status":0},
+		{"category":"火烧排查","name":"防火墙","status":0},
+		{"category":"外观检查","name":"左前门","status":1}
+	],
+	"defects":[{"zone":"左前门","type":"划痕"},{"zone":"后保险杠","type":"凹陷","desc":"轻微"}],
+	"paint":[{"part":"左前门","thickness":320},{"part":"发动机盖","thickness":110}]
+}})`
+
+func TestParseInspectionReport(t *testing.T) {
+	report, err := ParseInspectionReport(inspectionJSONP)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Transfers != 2 || len(report.Checks) != 5 || len(report.Defects) != 2 || len(report.Paint) != 2 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if report.Checks[1].Category != RiskAccident || report.Checks[1].Passed || report.Checks[2].Category != RiskFlood {
+		t.Errorf("unexpected checks %+v", report.Checks)
+	}
+	if report.Defects[1] != (Defect{Zone: "后保险杠", Kind: "凹陷", Note: "轻微"}) {
+		t.Errorf("unexpected defect %+v", report.Defects[1])
+	}
+
+	s := report.Summary()
+	if !s.Warning || len(s.Failed) != 1 || s.Failed[0] != RiskAccident {
+		t.Errorf("expected accident warning, got %+v", s)
+	}
+	if s.Checks != 5 || s.Passed != 3 || s.Defects != 2 || s.Repainted != 1 || s.Transfers != 2 {
+		t.Errorf("unexpected summary %+v", s)
+	}
+
+	// a failed cosmetic check is not serious
+	report.Checks[1].Passed = true
+	if report.Summary().Warning {
+		t.Errorf("unexpected warning without serious failures")
+	}
+}
+
+func TestParseInspectionReportMissing(t *testing.T) {
+	for _, body := range []string{
+		`{"returncode":0,"result":{"checkitems":[]}}`,
+		`inspection({"returncode":2010100,"message":"无检测报告","result":null})`,
+	} {
+		if _, err := ParseInspectionReport(body); !errors.Is(err, ErrNoReport) {
+			t.Errorf("expected ErrNoReport for %s, got %v", body, err)
+		}
+	}
+
+	report, err := ParseInspectionReport(`{"returncode":0,"result":{"checkitems":[{"category":"事故排查","name":"A柱","status":0}]}}`)
+	if err != nil || report.Transfers != -1 {
+		t.Errorf("unknown transfers should be -1, got %+v, %v", report, err)
+	}
+
+	if _, err := ParseInspectionReport("<html>"); err == nil || errors.Is(err, ErrNoReport) {
+		t.Errorf("expected parse error, got %v", err)
+	}
+}
@@ -0,0 +1,191 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"mashinki/logging"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// ErrLayoutChanged means that che168 changed the page markup and car data can't be found
+var ErrLayoutChanged = errors.New("che168 layout changed")
+
+// LayoutError names fields no selector found and keeps the page for debugging
+type LayoutError struct {
+	URL     string  // адрес страницы
+	Missing []Field // поля, которые не нашел ни один селектор
+	Page    string  // html страницы
+}
+
+func (e *LayoutError) Error() string {
+	names := make([]string, len(e.Missing))
+	for i, f := range e.Missing {
+		names[i] = string(f)
+	}
+	return fmt.Sprintf("%v: %s not found on %s", ErrLayoutChanged, strings.Join(names, ", "), e.URL)
+}
+
+// Is makes errors.Is(err, ErrLayoutChanged) true
+func (e *LayoutError) Is(target error) bool {
+	return target == ErrLayoutChanged
+}
+
+var (
+	layoutHandler   func(*LayoutError)
+	layoutHandlerMu sync.RWMutex
+)
+
+// OnLayoutChange sets the function called on every lookup that failed because che168 changed the markup,
+// whether the lookup came from the bot, the API, the CLI or a bulk file. nil only logs the error.
+func OnLayoutChange(f func(*LayoutError)) {
+	layoutHandlerMu.Lock()
+	layoutHandler = f
+	layoutHandlerMu.Unlock()
+}
+
+// layoutChanged logs the error and passes it to the handler
+func layoutChanged(err *LayoutError) {
+	logging.DefaultLogger.LogError(err)
+
+	layoutHandlerMu.RLock()
+	f := layoutHandler
+	layoutHandlerMu.RUnlock()
+	if f != nil {
+		f(err)
+	}
+}
+
+// fieldSpecID is the che168 spec of the car, it is only needed to get the specs
+const fieldSpecID Field = "spec_id"
+
+// requiredConfig are fields every CarConfig page has,
+// if any of them is not found the markup has changed
+var requiredConfig = []Field{FieldPrice, FieldName, FieldMileage, FieldYear}
+
+// selector finds a field on a che168 page, empty if not found
+type selector struct {
+	name string // для логов
+	find func(doc *goquery.Document) string
+}
+
+// configSelectors are tried in order: the desktop markup, then data embedded in scripts,
+// the mobile api is requested if they all fail
+var configSelectors = map[Field][]selector{
+	FieldPrice: {
+		inputValue("#car_price"),
+		scriptValue(`"price"\s*:\s*"?(\d+(?:\.\d+)?)`, "%s"),
+	},
+	fieldSpecID: {
+		inputValue("#CarSpecid"),
+		scriptValue(`"specid"\s*:\s*"?(\d+)`, "%s"),
+	},
+	FieldName: {
+		text(".source-info-con h3 a"),
+		text(".car-box h3"),
+		scriptValue(`"carname"\s*:\s*"([^"]+)"`, "%s"),
+	},
+	// info line looks like 3.5万公里／2021-05／...
+	FieldMileage: {
+		infoPart(0),
+		scriptValue(`"mileage"\s*:\s*"?(\d+(?:\.\d+)?)`, "%s万公里"),
+	},
+	FieldYear: {
+		infoPart(1),
+		scriptValue(`"firstregdate"\s*:\s*"(\d{4}-\d{2})`, "%s"),
+	},
+}
+
+func inputValue(css string) selector {
+	return selector{css + "[value]", func(doc *goquery.Document) string {
+		return strings.TrimSpace(doc.Find(css).AttrOr("value", ""))
+	}}
+}
+
+func text(css string) selector {
+	return selector{css, func(doc *goquery.Document) string {
+		return strings.TrimSpace(doc.Find(css).First().Text())
+	}}
+}
+
+func infoPart(i int) selector {
+	return selector{fmt.Sprintf(".source-info-con p[%d]", i), func(doc *goquery.Document) string {
+		parts := strings.Split(doc.Find(".source-info-con p").First().Text(), "／")
+		// the last part is not separated from the rest of the text
+		if len(parts) < i+2 {
+			return ""
+		}
+		return strings.TrimSpace(parts[i])
+	}}
+}
+
+// scriptValue finds the first group of the pattern in inline scripts and formats it
+func scriptValue(pattern, format string) selector {
+	re := regexp.MustCompile(pattern)
+	return selector{"script " + pattern, func(doc *goquery.Document) string {
+		var value string
+		doc.Find("script").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+			if m := re.FindStringSubmatch(s.Text()); m != nil {
+				value = fmt.Sprintf(format, m[1])
+			}
+			return value == ""
+		})
+		return value
+	}}
+}
+
+// pageConfig are raw values of CarConfig fields
+type pageConfig map[Field]string
+
+// find tries selectors of every field on the page, values found earlier are kept
+func (c pageConfig) find(doc *goquery.Document) {
+	for f, selectors := range configSelectors {
+		if c[f] != "" {
+			continue
+		}
+		for i, s := range selectors {
+			if value := s.find(doc); value != "" {
+				if i > 0 {
+					logging.DefaultLogger.LogErrorF("che168 layout: %s found by fallback %s", f, s.name)
+				}
+				c[f] = value
+				break
+			}
+		}
+	}
+}
+
+// missing lists required fields that are not found or make no sense
+func (c pageConfig) missing() []Field {
+	var missing []Field
+	for _, f := range requiredConfig {
+		if c[f] == "" {
+			missing = append(missing, f)
+		}
+	}
+	// zero price means that the selector points to something else
+	if price, err := strconv.ParseFloat(c[FieldPrice], 64); c[FieldPrice] != "" && (err != nil || price <= 0) {
+		missing = append(missing, FieldPrice)
+	}
+	return missing
+}
+
+// removedMarkers are texts of che168 pages of sold or removed listings
+var removedMarkers = []string{"已下架", "已售出", "车源不存在", "该车已售"}
+
+// removedPage tells whether the page is about a sold or removed listing
+func removedPage(c pageConfig, page string) bool {
+	if c[FieldPrice] != "" || c[fieldSpecID] != "" {
+		return false
+	}
+	for _, marker := range removedMarkers {
+		if strings.Contains(page, marker) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func findConfig(t *testing.T, html string) pageConfig {
+	t.Helper()
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("error while parsing html: %v", err)
+	}
+	config := pageConfig{}
+	config.find(doc)
+	return config
+}
+
+func TestPageConfig(t *testing.T) {
+	desktop := findConfig(t, `<input id="car_price" value="15.8"><input id="CarSpecid" value="42">
+<div class="source-info-con"><h3><a>奥迪A4L 2021款</a></h3><p>3.5万公里／2021-05／北京</p></div>`)
+	want := pageConfig{FieldPrice: "15.8", fieldSpecID: "42", FieldName: "奥迪A4L 2021款", FieldMileage: "3.5万公里", FieldYear: "2021-05"}
+	if !reflect.DeepEqual(desktop, want) || len(desktop.missing()) != 0 {
+		t.Errorf("expected %v, got %v", want, desktop)
+	}
+
+	// the mobile page keeps data in scripts
+	mobile := findConfig(t, `<script>window.__DATA__ = {"carname":"奥迪A4L 2021款","price":"15.8","specid":42,
+"mileage":3.5,"firstregdate":"2021-05-01"}</script>`)
+	if !reflect.DeepEqual(mobile, want) {
+		t.Errorf("expected %v, got %v", want, mobile)
+	}
+
+	broken := findConfig(t, `<input id="car_price" value="0"><div class="new-layout"><h1>奥迪A4L</h1></div>`)
+	if missing := broken.missing(); !reflect.DeepEqual(missing, []Field{FieldName, FieldMileage, FieldYear, FieldPrice}) {
+		t.Errorf("unexpected missing fields %v", missing)
+	}
+}
+
+func TestRemovedPage(t *testing.T) {
+	if !removedPage(pageConfig{}, "<p>该车源已下架</p>") {
+		t.Errorf("expected removed listing")
+	}
+	if removedPage(pageConfig{}, "<p>new layout</p>") {
+		t.Errorf("page without markers is not removed")
+	}
+	if removedPage(pageConfig{FieldPrice: "15.8"}, "<p>相似车源已售出</p>") {
+		t.Errorf("page with a price is not removed")
+	}
+}
+
+func TestLayoutError(t *testing.T) {
+	err := fmt.Errorf("failed to get car config: %w", &LayoutError{URL: "https://www.che168.com/1.html", Missing: []Field{FieldPrice, FieldName}})
+	if !errors.Is(err, ErrLayoutChanged) || errors.Is(err, ErrListingRemoved) {
+		t.Errorf("unexpected error kind: %v", err)
+	}
+	if !strings.Contains(err.Error(), "price, full_name not found on https://www.che168.com/1.html") {
+		t.Errorf("unexpected message %q", err)
+	}
+}
+
+func TestOnLayoutChange(t *testing.T) {
+	var got *LayoutError
+	OnLayoutChange(func(err *LayoutError) { got = err })
+	defer OnLayoutChange(nil)
+
+	err := &LayoutError{URL: "https://www.che168.com/1.html", Missing: []Field{FieldPrice}}
+	layoutChanged(err)
+	if got != err {
+		t.Errorf("handler was not called")
+	}
+
+	OnLayoutChange(nil)
+	layoutChanged(err)
+}
@@ -0,0 +1,164 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MileageUnit is the unit the mileage was written in on che168
+type MileageUnit string
+
+const (
+	UnitUnknown       MileageUnit = ""
+	UnitKm            MileageUnit = "km"          // 公里, km
+	UnitHundredKm     MileageUnit = "hundred_km"  // 百公里
+	UnitThousandKm    MileageUnit = "thousand_km" // 千公里
+	UnitTenThousandKm MileageUnit = "wan_km"      // 万公里
+)
+
+var unitFactors = map[MileageUnit]float64{
+	UnitKm:            1,
+	UnitHundredKm:     100,
+	UnitThousandKm:    1000,
+	UnitTenThousandKm: 10_000,
+}
+
+// MileageConfidence tells how much the parsed mileage can be trusted
+type MileageConfidence string
+
+const (
+	ConfidenceExact       MileageConfidence = "exact"       // число с единицей измерения
+	ConfidenceApproximate MileageConfidence = "approximate" // граница, диапазон, «около» или единица угадана
+	ConfidenceUnknown     MileageConfidence = "unknown"     // пробег не найден
+)
+
+// Mileage is the mileage of the car in kilometers
+type Mileage struct {
+	Km         int               `json:"km"`
+	Unit       MileageUnit       `json:"unit"`
+	Confidence MileageConfidence `json:"confidence"`
+	Raw        string            `json:"raw,omitempty"` // как указано в объявлении
+}
+
+var (
+	mileageNumberRegexp = regexp.MustCompile(`\d+(?:\.\d+)?`)
+	// thousands separators: 12,000公里
+	mileageSeparatorRegexp = regexp.MustCompile(`(\d),(\d{3})`)
+
+	// words of bounds, ranges and estimates, «<1万公里» is up to 10 000 km
+	approximateWords = []string{"<", ">", "小于", "少于", "不足", "不到", "以内", "以下", "以上", "超过", "约", "左右", "大约", "-", "~", "至"}
+	newCarWords      = []string{"准新车", "新车", "零公里"}
+)
+
+// ParseMileage parses every mileage format of che168:
+// 3.5万公里, 0.01万公里, <1万公里, 5百公里, 1200公里, 12,000 km, 1-3万公里, ３万公里.
+// The upper value is used for bounds and ranges.
+func ParseMileage(raw string) Mileage {
+	m := Mileage{Raw: strings.TrimSpace(raw), Confidence: ConfidenceUnknown}
+	s := normalizeMileage(m.Raw)
+	if s == "" {
+		return m
+	}
+
+	numbers := mileageNumberRegexp.FindAllString(s, -1)
+	if len(numbers) == 0 {
+		for _, word := range newCarWords {
+			if strings.Contains(s, word) {
+				m.Unit, m.Confidence = UnitKm, ConfidenceApproximate
+				return m
+			}
+		}
+		return m
+	}
+	value, err := strconv.ParseFloat(numbers[len(numbers)-1], 64)
+	if err != nil {
+		return m
+	}
+
+	m.Confidence = ConfidenceExact
+	switch {
+	case strings.Contains(s, "万"):
+		m.Unit = UnitTenThousandKm
+	case strings.Contains(s, "千"):
+		m.Unit = UnitThousandKm
+	case strings.Contains(s, "百"):
+		m.Unit = UnitHundredKm
+	case strings.Contains(s, "公里"), strings.Contains(s, "km"), strings.Contains(s, "км"):
+		m.Unit = UnitKm
+	case value < 100:
+		// che168 cards without the unit show 万公里
+		m.Unit, m.Confidence = UnitTenThousandKm, ConfidenceApproximate
+	default:
+		m.Unit, m.Confidence = UnitKm, ConfidenceApproximate
+	}
+
+	for _, word := range approximateWords {
+		if strings.Contains(s, word) {
+			m.Confidence = ConfidenceApproximate
+			break
+		}
+	}
+
+	m.Km = int(math.Round(value * unitFactors[m.Unit]))
+	return m
+}
+
+// normalizeMileage makes full-width symbols ASCII and drops spaces and thousands separators
+func normalizeMileage(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case r >= '０' && r <= '９':
+			return r - '０' + '0'
+		case r == '．':
+			return '.'
+		case r == '＜':
+			return '<'
+		case r == '＞':
+			return '>'
+		case r == '，':
+			return ','
+		case r == '～' || r == '—' || r == '–':
+			return '~'
+		case r == ' ' || r == ' ' || r == '　' || r == '\t':
+			return -1
+		}
+		return r
+	}, s)
+	s = mileageSeparatorRegexp.ReplaceAllString(s, "$1$2")
+	return strings.ToLower(s)
+}
+
+// Known tells whether the mileage was found, a zero Mileage is unknown
+func (m Mileage) Known() bool {
+	return m.Confidence == ConfidenceExact || m.Confidence == ConfidenceApproximate
+}
+
+// Format shows the mileage with the unit name: 35000 км, ≈10000 км.
+// Unknown mileage is shown as it was written on che168.
+func (m Mileage) Format(unit string) string {
+	switch {
+	case !m.Known() && m.Raw != "":
+		return m.Raw
+	case !m.Known():
+		return "—"
+	case m.Confidence == ConfidenceApproximate:
+		return fmt.Sprintf("≈%d %s", m.Km, unit)
+	}
+	return fmt.Sprintf("%d %s", m.Km, unit)
+}
+
+// UnmarshalJSON also reads mileage saved as a formatted string like "35000 км"
+func (m *Mileage) UnmarshalJSON(data []byte) error {
+	var legacy string
+	if err := json.Unmarshal(data, &legacy); err == nil {
+		*m = ParseMileage(legacy)
+		return nil
+	}
+
+	type mileage Mileage
+	return json.Unmarshal(data, (*mileage)(m))
+}
@@ -0,0 +1,83 @@
+package parser
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseMileage(t *testing.T) {
+	tests := []struct {
+		raw        string
+		km         int
+		unit       MileageUnit
+		confidence MileageConfidence
+	}{
+		{"3.5万公里", 35_000, UnitTenThousandKm, ConfidenceExact},
+		{" 12万公里 ", 120_000, UnitTenThousandKm, ConfidenceExact},
+		{"0.01万公里", 100, UnitTenThousandKm, ConfidenceExact},
+		{"0.5 万公里", 5_000, UnitTenThousandKm, ConfidenceExact},
+		{"<1万公里", 10_000, UnitTenThousandKm, ConfidenceApproximate},
+		{"＜1万公里", 10_000, UnitTenThousandKm, ConfidenceApproximate},
+		{"小于1万公里", 10_000, UnitTenThousandKm, ConfidenceApproximate},
+		{"不足1万公里", 10_000, UnitTenThousandKm, ConfidenceApproximate},
+		{"1-3万公里", 30_000, UnitTenThousandKm, ConfidenceApproximate},
+		{"约2.3万公里", 23_000, UnitTenThousandKm, ConfidenceApproximate},
+		{"３．６万公里", 36_000, UnitTenThousandKm, ConfidenceExact},
+		{"3.6万", 36_000, UnitTenThousandKm, ConfidenceExact},
+		{"5百公里", 500, UnitHundredKm, ConfidenceExact},
+		{"8千公里", 8_000, UnitThousandKm, ConfidenceExact},
+		{"1200公里", 1_200, UnitKm, ConfidenceExact},
+		{"12,000 km", 12_000, UnitKm, ConfidenceExact},
+		{"0公里", 0, UnitKm, ConfidenceExact},
+		{"35000 км", 35_000, UnitKm, ConfidenceExact},
+		{"3.5", 35_000, UnitTenThousandKm, ConfidenceApproximate},
+		{"4500", 4_500, UnitKm, ConfidenceApproximate},
+		{"准新车", 0, UnitKm, ConfidenceApproximate},
+		{"", 0, UnitUnknown, ConfidenceUnknown},
+		{"暂无", 0, UnitUnknown, ConfidenceUnknown},
+	}
+
+	for _, tt := range tests {
+		m := ParseMileage(tt.raw)
+		if m.Km != tt.km || m.Unit != tt.unit || m.Confidence != tt.confidence {
+			t.Errorf("%q: expected %d %q %s, got %d %q %s", tt.raw, tt.km, tt.unit, tt.confidence, m.Km, m.Unit, m.Confidence)
+		}
+	}
+}
+
+func TestMileageFormat(t *testing.T) {
+	if got := ParseMileage("3.5万公里").Format("км"); got != "35000 км" {
+		t.Errorf("unexpected exact mileage %q", got)
+	}
+	if got := ParseMileage("<1万公里").Format("km"); got != "≈10000 km" {
+		t.Errorf("unexpected approximate mileage %q", got)
+	}
+	if got := ParseMileage("暂无").Format("km"); got != "暂无" {
+		t.Errorf("unknown mileage should be shown as is, got %q", got)
+	}
+	if got := (Mileage{}).Format("km"); got != "—" || (Mileage{}).Known() {
+		t.Errorf("zero mileage should be unknown, got %q", got)
+	}
+}
+
+func TestMileageJSON(t *testing.T) {
+	var car CarInfo
+	// snapshots saved before mileage was parsed keep the formatted string
+	if err := json.Unmarshal([]byte(`{"mileage": "35000 км"}`), &car); err != nil {
+		t.Fatal(err)
+	}
+	if car.Mileage.Km != 35_000 || car.Mileage.Unit != UnitKm {
+		t.Errorf("unexpected legacy mileage %+v", car.Mileage)
+	}
+
+	data, err := json.Marshal(CarInfo{Mileage: ParseMileage("<1万公里")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &car); err != nil {
+		t.Fatal(err)
+	}
+	if car.Mileage != ParseMileage("<1万公里") {
+		t.Errorf("mileage changed after encoding: %+v", car.Mileage)
+	}
+}
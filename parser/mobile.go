@@ -0,0 +1,132 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// mobileAPIURL is the api of the che168 mobile site with listing data in json
+const mobileAPIURL = "https://apiuscdt.che168.com/apic/v2/car/getcarinfo?infoid=%s&_appid=2sc.m"
+
+// mobileValue is a value the mobile api sends either as a string or as a number
+type mobileValue string
+
+func (v *mobileValue) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*v = ""
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*v = mobileValue(strings.TrimSpace(s))
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("error while parsing value %s: %v", data, err)
+	}
+	*v = mobileValue(n.String())
+	return nil
+}
+
+// mobileCar is the listing from the mobile api
+type mobileCar struct {
+	CarName      mobileValue `json:"carname"`
+	Price        mobileValue `json:"price"`   // в 万 юаней
+	Mileage      mobileValue `json:"mileage"` // в 万 км
+	FirstRegDate mobileValue `json:"firstregdate"`
+	SpecID       mobileValue `json:"specid"`
+	DealerID     mobileValue `json:"dealerid"`
+	DealerName   mobileValue `json:"dealername"`
+	CityName     mobileValue `json:"cityname"`
+	ProvinceName mobileValue `json:"provincename"`
+	PublicDate   mobileValue `json:"publicdate"`
+	Pictures     []string    `json:"piclist"`
+}
+
+type mobileResponse struct {
+	ReturnCode int       `json:"returncode"`
+	Message    string    `json:"message"`
+	Result     mobileCar `json:"result"`
+}
+
+// getMobileCar requests the listing from the mobile api
+func getMobileCar(carID string) (*mobileCar, error) {
+	body, err := makeRequest(fmt.Sprintf(mobileAPIURL, carID), 2)
+	if err != nil {
+		return nil, err
+	}
+	return parseMobileCar([]byte(body))
+}
+
+func parseMobileCar(data []byte) (*mobileCar, error) {
+	var resp mobileResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("error while parsing mobile api response: %v", err)
+	}
+	if resp.ReturnCode != 0 {
+		return nil, fmt.Errorf("mobile api returned code %d: %s", resp.ReturnCode, resp.Message)
+	}
+	return &resp.Result, nil
+}
+
+// fill sets fields of the config that were not found on the desktop page
+func (m *mobileCar) fill(c pageConfig) {
+	values := pageConfig{
+		FieldPrice:  string(m.Price),
+		fieldSpecID: string(m.SpecID),
+		FieldName:   string(m.CarName),
+	}
+	if m.Mileage != "" {
+		values[FieldMileage] = string(m.Mileage) + "万公里"
+	}
+	// dates come as 2021-05 or 2021-05-01
+	if date := string(m.FirstRegDate); len(date) >= 7 {
+		values[FieldYear] = date[:7]
+	}
+
+	for f, value := range values {
+		if c[f] == "" && value != "" {
+			c[f] = value
+		}
+	}
+}
+
+// photos returns gallery photos in the original size, each photo once
+func (m *mobileCar) photos() []string {
+	var photos []string
+	seen := make(map[string]bool)
+	for _, src := range m.Pictures {
+		name := photoNameRegexp.FindString(src)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		photos = append(photos, PhotoURL(absoluteURL(src), PhotoOriginal))
+	}
+	return photos
+}
+
+// fillSeller sets seller fields that were not found on the desktop page
+func (m *mobileCar) fillSeller(s *Seller) {
+	set := func(field *string, value string) {
+		if *field == "" {
+			*field = value
+		}
+	}
+	if id := string(m.DealerID); id != "0" {
+		set(&s.DealerID, id)
+	}
+	set(&s.Name, string(m.DealerName))
+	set(&s.City, strings.TrimSuffix(string(m.CityName), "市"))
+	set(&s.Province, strings.TrimSuffix(strings.TrimSuffix(string(m.ProvinceName), "省"), "市"))
+	if date := string(m.PublicDate); len(date) >= 10 {
+		set(&s.Published, date[:10])
+	}
+
+	if s.DealerID != "" {
+		s.Type = SellerDealer
+	}
+}
@@ -0,0 +1,75 @@
+package parser
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+const mobileResponseJSON = `{"returncode": 0, "message": "", "result": {
+	"carname": "奥迪A4L 2021款", "price": 15.8, "mileage": "3.5", "firstregdate": "2021-05-01",
+	"specid": 42, "dealerid": 123, "dealername": "北京车行", "cityname": "北京市", "provincename": "北京",
+	"publicdate": "2025-05-01 10:00:00",
+	"piclist": ["//2sc2.autoimg.cn/escimg/640x480_0_q87_autohomecar__a.jpg",
+		"https://2sc2.autoimg.cn/escimg/autohomecar__a.jpg", "https://2sc2.autoimg.cn/escimg/autohomecar__b.png"]
+}}`
+
+func TestMobileCar(t *testing.T) {
+	m, err := parseMobileCar([]byte(mobileResponseJSON))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// values found on the desktop page are kept
+	config := pageConfig{FieldName: "奥迪A4L"}
+	m.fill(config)
+	want := pageConfig{FieldPrice: "15.8", fieldSpecID: "42", FieldName: "奥迪A4L", FieldMileage: "3.5万公里", FieldYear: "2021-05"}
+	if !reflect.DeepEqual(config, want) || len(config.missing()) != 0 {
+		t.Errorf("expected %v, got %v", want, config)
+	}
+
+	photos := []string{"https://2sc2.autoimg.cn/escimg/autohomecar__a.jpg", "https://2sc2.autoimg.cn/escimg/autohomecar__b.png"}
+	if got := m.photos(); !reflect.DeepEqual(got, photos) {
+		t.Errorf("expected %v, got %v", photos, got)
+	}
+
+	s := Seller{Type: SellerPrivate, Badges: []string{"certified"}}
+	m.fillSeller(&s)
+	wantSeller := Seller{Type: SellerDealer, DealerID: "123", Name: "北京车行", City: "北京", Province: "北京",
+		Published: "2025-05-01", Badges: []string{"certified"}}
+	if !reflect.DeepEqual(s, wantSeller) {
+		t.Errorf("expected %+v, got %+v", wantSeller, s)
+	}
+
+	if _, err := parseMobileCar([]byte(`{"returncode": 2049, "message": "车源不存在"}`)); err == nil {
+		t.Errorf("expected error for a failed response")
+	}
+}
+
+func TestDecodeBody(t *testing.T) {
+	gbk, err := simplifiedchinese.GBK.NewEncoder().String("奥迪")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		contentType string
+		body        string
+	}{
+		{"text/html", gbk},
+		{"text/html; charset=gb2312", gbk},
+		{"text/html; charset=GBK", gbk},
+		{"text/html; charset=utf-8", "奥迪"},
+		{"application/json", "奥迪"},
+		{"application/json;charset=UTF-8", "奥迪"},
+	}
+	for _, tt := range tests {
+		got, err := io.ReadAll(decodeBody(strings.NewReader(tt.body), tt.contentType))
+		if err != nil || string(got) != "奥迪" {
+			t.Errorf("%s: expected 奥迪, got %q %v", tt.contentType, got, err)
+		}
+	}
+}
@@ -2,17 +2,21 @@ package parser
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"mashinki/logging"
 	"mashinki/translations"
 	"net/url"
-	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/PuerkitoBio/goquery"
 )
 
+// ErrListingRemoved means that the listing was sold or removed from che168
+var ErrListingRemoved = errors.New("listing was removed")
+
 // Map of known drive types
 var driveTypes = map[string]string{
 	"中置四驱": "Полный привод",
@@ -22,27 +26,27 @@ var driveTypes = map[string]string{
 	"后置后驱": "Задний привод",
 }
 
-// parseMileage extracts numeric value and converts according to units
-func parseMileage(mileageStr string) string {
-	// Remove spaces
-	mileageStr = strings.TrimSpace(mileageStr)
+// Known drive types in English
+var driveTypesEn = map[string]string{
+	"中置四驱": "All-wheel drive",
+	"前置后驱": "Front-engine rear-wheel drive",
+	"前置前驱": "Front-wheel drive",
+	"中置后驱": "Mid-engine rear-wheel drive",
+	"后置后驱": "Rear-wheel drive",
+}
 
-	// Find number in the string
-	re := regexp.MustCompile(`[\d.]+`)
-	numbers := re.FindString(mileageStr)
-	if numbers == "" {
-		return mileageStr
-	}
+// Not registered car instead of the registration year
+var notRegistered = map[string]string{
+	"ru": "Еще не ставился на учет",
+	"en": "Not registered yet",
+}
 
-	value, err := strconv.ParseFloat(numbers, 64)
-	if err != nil {
-		return mileageStr
+// textSource is the source of texts translated to the language
+func textSource(lang string) Source {
+	if lang == "zh" {
+		return SourceScraped
 	}
-
-	// Convert to kilometers
-	totalKm := value * 10000
-
-	return fmt.Sprintf("%.0f км", totalKm)
+	return SourceTranslated
 }
 
 // getCarId extracts car ID from the page URL
@@ -71,7 +75,7 @@ func getCarId(urlStr string) (string, error) {
 }
 
 // getCarConfig retrieves basic car information: name, price, year, mileage
-func getCarConfig(url string, CI *CarInfo) error {
+func getCarConfig(url string, CI *CarInfo, lang string) error {
 	var err error
 	CI.CarId, err = getCarId(url)
 	if err != nil {
@@ -90,41 +94,86 @@ func getCarConfig(url string, CI *CarInfo) error {
 		return fmt.Errorf("error while parsing html: %v", err)
 	}
 
-	// Getting spec id
-	CI.SpecID = doc.Find("#CarSpecid").AttrOr("value", "")
+	// the mobile api has the same data, it is requested once
+	// if something is not found on the desktop page
+	var mobile *mobileCar
+	var mobileRequested bool
+	getMobile := func() *mobileCar {
+		if !mobileRequested {
+			mobileRequested = true
+			var err error
+			if mobile, err = getMobileCar(CI.CarId); err != nil {
+				logging.DefaultLogger.LogErrorF("Error requesting mobile api for %s: %v", CI.CarId, err)
+			}
+		}
+		return mobile
+	}
 
-	// Getting car price
-	priceStr := doc.Find("#car_price").AttrOr("value", "0")
-	priceConv, err := strconv.ParseFloat(priceStr, 64)
-	if err != nil {
-		return fmt.Errorf("failed to parse price: %v", err)
+	config := pageConfig{}
+	config.find(doc)
+	if len(config.missing()) > 0 {
+		// listings that were sold or removed have no car data
+		if removedPage(config, resp) {
+			return ErrListingRemoved
+		}
+		if m := getMobile(); m != nil {
+			m.fill(config)
+		}
+		if missing := config.missing(); len(missing) > 0 {
+			err := &LayoutError{URL: carInfoUrl, Missing: missing, Page: resp}
+			layoutChanged(err)
+			return err
+		}
 	}
+
+	// Getting gallery photos
+	CI.Photos = parsePhotos(doc)
+	if len(CI.Photos) == 0 {
+		if m := getMobile(); m != nil {
+			CI.Photos = m.photos()
+		}
+	}
+
+	// Getting seller and location
+	CI.Seller = parseSeller(doc, url)
+	if CI.Seller.City == "" || CI.Seller.Published == "" {
+		if m := getMobile(); m != nil {
+			m.fillSeller(&CI.Seller)
+		}
+	}
+
+	// Getting spec id
+	CI.SpecID = config[fieldSpecID]
+
+	// Getting car price, missing checks that it is a positive number
+	priceConv, _ := strconv.ParseFloat(config[FieldPrice], 64)
 	CI.Price = priceConv * 10_000
+	CI.SetSource(FieldPrice, SourceScraped)
 
 	// getting full car name
-	fullName := doc.Find(".source-info-con h3 a").Text()
-	CI.FullName = translations.Translate(fullName)
+	CI.FullName = translations.TranslateTo(config[FieldName], lang)
+	if CI.FullName != "" {
+		CI.SetSource(FieldName, textSource(lang))
+	}
 
 	// getting mileage and year
-	infoText := doc.Find(".source-info-con p").First().Text()
-	if idx := strings.Index(infoText, "／"); idx != -1 {
-		CI.Milage = parseMileage(strings.TrimSpace(infoText[:idx]))
-
-		// Getting year
-		rest := infoText[idx+3:] // skipping the first symbol
-		if idx2 := strings.Index(rest, "／"); idx2 != -1 {
-			CI.Year = strings.TrimSpace(rest[:idx2])
-			if CI.Year == "未上牌" {
-				CI.Year = "Еще не ставился на учет"
-			}
-		}
+	CI.Mileage = ParseMileage(config[FieldMileage])
+	if CI.Mileage.Known() {
+		CI.SetSource(FieldMileage, SourceScraped)
+	}
+
+	CI.Year = config[FieldYear]
+	CI.SetSource(FieldYear, SourceScraped)
+	if text, ok := notRegistered[lang]; ok && CI.Year == "未上牌" {
+		CI.Year = text
+		CI.SetSource(FieldYear, SourceTranslated)
 	}
 
 	return nil
 }
 
 // getCarSpecInfo retrieves technical specifications: power, engine size, drive type, fuel type
-func getCarSpecInfo(CI *CarInfo) error {
+func getCarSpecInfo(CI *CarInfo, lang string) error {
 	carSpecUrl := fmt.Sprintf("https://cacheapigo.che168.com/CarProduct/GetParam.ashx?specid=%s&callback=configTitle", CI.SpecID)
 	specs, err := makeRequest(carSpecUrl, 1)
 	if err != nil {
@@ -145,68 +194,113 @@ func getCarSpecInfo(CI *CarInfo) error {
 	}
 
 	// Searching for power and engine size in characteristics
+	var electric bool
 	if specsJSON != nil && len(specsJSON.Result.ParamTypeItems) > 0 {
 		for _, group := range specsJSON.Result.ParamTypeItems {
 			for _, param := range group.ParamItems {
 				name := param.Name
 				value := param.Value
 
-				// Searching for power by (kW), taking maximum value
-				if strings.Contains(name, "(kW)") {
-					if intVal, err := strconv.Atoi(value); err == nil {
-						if intVal > CI.Power {
-							CI.Power = intVal
-						}
-					}
+				// Power and torque of the engine and motors
+				if CI.Powertrain.set(param) {
+					continue
 				}
 
 				// Searching for engine size by (mL)
 				if strings.Contains(name, "(mL)") {
 					if intVal, err := strconv.Atoi(value); err == nil {
 						CI.EngineSize = intVal
+						CI.SetSource(FieldEngineSize, SourceScraped)
 					}
 				}
 
 				// Searching for drive type
 				if strings.Contains(name, "驱动方式") {
-					knownType, exists := driveTypes[value]
+					known := driveTypes
+					if lang == "en" {
+						known = driveTypesEn
+					}
+					knownType, exists := known[value]
 					if exists {
 						CI.Drive = knownType
 					} else {
 						logging.TranslationsLogger.LogErrorF("Unknown type of drive: %v", value)
-						CI.Drive = translations.Translate(value)
+						CI.Drive = translations.TranslateTo(value, lang)
+					}
+					if CI.Drive != "" {
+						CI.SetSource(FieldDrive, textSource(lang))
 					}
 				}
 
 				// Searching for fuel type
 				if strings.Contains(name, "燃料形式") {
-					CI.FuelType = translations.Translate(value)
+					CI.FuelType = translations.TranslateTo(value, lang)
+					if CI.FuelType != "" {
+						CI.SetSource(FieldFuelType, textSource(lang))
+					}
+					electric = strings.Contains(value, "纯电")
 				}
 			}
 		}
 	}
+
+	if power := CI.Powertrain.Total(); power > 0 {
+		CI.SetPower(power)
+		CI.SetSource(FieldPower, SourceScraped)
+	}
+
+	// electric cars have no engine size in specs
+	if electric && !CI.Has(FieldEngineSize) {
+		CI.EngineSize = 0
+		CI.SetSource(FieldEngineSize, SourceDefault)
+	}
 	return nil
 }
 
 // GetCarInfo retrieves complete car information by URL.
 // Returns a structure with car information or an error if something went wrong.
 func GetCarInfo(url string) (CarInfo, error) {
+	return GetCarInfoIn(url, "ru")
+}
+
+// GetCarInfoIn retrieves complete car information by URL
+// with texts translated to the language
+func GetCarInfoIn(url string, lang string) (CarInfo, error) {
 	carInformation := &CarInfo{}
 
 	// getting full name, price, year, mileage
-	err := getCarConfig(url, carInformation)
+	err := getCarConfig(url, carInformation, lang)
 	if err != nil {
-		return CarInfo{}, fmt.Errorf("failed to get car config: %v", err)
+		return CarInfo{}, fmt.Errorf("failed to get car config: %w", err)
 	}
 
 	if carInformation.SpecID != "" {
 		// getting car power, engine size, drive, fuel type
-		err = getCarSpecInfo(carInformation)
+		err = getCarSpecInfo(carInformation, lang)
 		if err != nil {
 			return CarInfo{}, fmt.Errorf("failed to get car specs: %v", err)
 		}
-		return *carInformation, nil
 	}
 
-	return CarInfo{}, fmt.Errorf("failed to get car Info: %v", err)
+	// cars without specs are still useful, missing fields are reported by Validate
+	return *carInformation, nil
+}
+
+// GetCarsInfo retrieves information about several cars concurrently in the language.
+// Results and errors are returned in the same order as the urls.
+func GetCarsInfo(urls []string, lang string) ([]CarInfo, []error) {
+	cars := make([]CarInfo, len(urls))
+	errs := make([]error, len(urls))
+
+	var wg sync.WaitGroup
+	for i, u := range urls {
+		wg.Add(1)
+		go func(i int, u string) {
+			defer wg.Done()
+			cars[i], errs[i] = GetCarInfoIn(u, lang)
+		}(i, u)
+	}
+	wg.Wait()
+
+	return cars, errs
 }
@@ -0,0 +1,75 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// PhotoSize is a resolution of listing photos served by the autohome image server
+type PhotoSize string
+
+const (
+	PhotoSmall    PhotoSize = "240x180_0_q87_"
+	PhotoMedium   PhotoSize = "640x480_0_q87_"
+	PhotoLarge    PhotoSize = "1024x0_1_q95_"
+	PhotoOriginal PhotoSize = ""
+)
+
+// ParsePhotoSize returns the size by its name: small, medium, large or original.
+// Unknown names mean the large size.
+func ParsePhotoSize(name string) PhotoSize {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "small":
+		return PhotoSmall
+	case "medium":
+		return PhotoMedium
+	case "original":
+		return PhotoOriginal
+	}
+	return PhotoLarge
+}
+
+// photo file names look like 640x480_0_q87_autohomecar__ChsEe...jpg,
+// the original is the same name without the size prefix
+var (
+	photoNameRegexp = regexp.MustCompile(`autohomecar__[\w-]+\.(?:jpe?g|png|webp)`)
+	photoSizeRegexp = regexp.MustCompile(`[^/]*autohomecar__`)
+)
+
+// PhotoURL returns the address of the photo in the size
+func PhotoURL(photoURL string, size PhotoSize) string {
+	return photoSizeRegexp.ReplaceAllLiteralString(photoURL, string(size)+"autohomecar__")
+}
+
+// parsePhotos collects gallery photos of the listing in page order, each photo once
+func parsePhotos(doc *goquery.Document) []string {
+	var photos []string
+	seen := make(map[string]bool)
+
+	doc.Find("img").Each(func(_ int, img *goquery.Selection) {
+		// galleries load lazily, the real address is in data attributes
+		for _, attr := range []string{"data-original", "src2", "data-src", "src"} {
+			src := img.AttrOr(attr, "")
+			name := photoNameRegexp.FindString(src)
+			if name == "" {
+				continue
+			}
+			if !seen[name] {
+				seen[name] = true
+				photos = append(photos, PhotoURL(absoluteURL(src), PhotoOriginal))
+			}
+			return
+		}
+	})
+	return photos
+}
+
+// absoluteURL adds the scheme to protocol-relative addresses
+func absoluteURL(src string) string {
+	if strings.HasPrefix(src, "//") {
+		return "https:" + src
+	}
+	return src
+}
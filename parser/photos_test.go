@@ -0,0 +1,45 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+const galleryHTML = `<html><body>
+<div class="car-pic-list">
+  <img src="//x.autoimg.cn/2sc/2015/blank.gif" data-original="//2sc2.autoimg.cn/escimg/g29/M06/AB/CD/640x480_0_q87_autohomecar__ChxkmWQabc-1.jpg">
+  <img src="https://2sc2.autoimg.cn/escimg/g29/M01/AB/CD/240x180_0_q87_autohomecar__ChxkmWQdef2.jpg">
+  <img src2="//2sc2.autoimg.cn/escimg/g29/M06/AB/CD/autohomecar__ChxkmWQabc-1.jpg">
+</div>
+<img src="//x.autoimg.cn/2sc/logo.png">
+</body></html>`
+
+func TestParsePhotos(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(galleryHTML))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	photos := parsePhotos(doc)
+	want := []string{
+		"https://2sc2.autoimg.cn/escimg/g29/M06/AB/CD/autohomecar__ChxkmWQabc-1.jpg",
+		"https://2sc2.autoimg.cn/escimg/g29/M01/AB/CD/autohomecar__ChxkmWQdef2.jpg",
+	}
+	if len(photos) != len(want) {
+		t.Fatalf("expected %d photos, got %v", len(want), photos)
+	}
+	for i := range want {
+		if photos[i] != want[i] {
+			t.Errorf("photo %d: expected %q, got %q", i, want[i], photos[i])
+		}
+	}
+
+	if got := PhotoURL(photos[0], ParsePhotoSize("medium")); got != "https://2sc2.autoimg.cn/escimg/g29/M06/AB/CD/640x480_0_q87_autohomecar__ChxkmWQabc-1.jpg" {
+		t.Errorf("unexpected medium photo %q", got)
+	}
+	if ParsePhotoSize("unknown") != PhotoLarge || ParsePhotoSize("Original") != PhotoOriginal {
+		t.Errorf("unexpected photo sizes")
+	}
+}
@@ -0,0 +1,120 @@
+package parser
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// HpPerKw converts kilowatts to horsepower the way customs do: 1 hp = 0.7355 kW
+const HpPerKw = 1.35962
+
+// KwToHp converts power in kilowatts to horsepower rounded to tenths
+func KwToHp(kw int) float64 {
+	return math.Round(float64(kw)*HpPerKw*10) / 10
+}
+
+// HpToKw converts power in horsepower to whole kilowatts
+func HpToKw(hp float64) int {
+	return int(math.Round(hp / HpPerKw))
+}
+
+// Powertrain is power and torque of the engine and electric motors from che168 specs.
+// Zero means that the value is not in the specs.
+type Powertrain struct {
+	EngineKw       int `json:"engine_kw,omitempty"`        // ДВС
+	MotorKw        int `json:"motor_kw,omitempty"`         // все электромоторы
+	FrontMotorKw   int `json:"front_motor_kw,omitempty"`   // передний электромотор
+	RearMotorKw    int `json:"rear_motor_kw,omitempty"`    // задний электромотор
+	CombinedKw     int `json:"combined_kw,omitempty"`      // системная мощность гибрида
+	EngineTorqueNm int `json:"engine_torque_nm,omitempty"` // в Н·м
+	MotorTorqueNm  int `json:"motor_torque_nm,omitempty"`
+	CombinedTorque int `json:"combined_torque_nm,omitempty"`
+}
+
+// powerParam is a che168 spec param with power or torque
+type powerParam struct {
+	id   int
+	name string
+	set  func(p *Powertrain, value int)
+}
+
+// powerParams maps che168 spec params by id, names are used
+// if the id is not in the response
+var powerParams = []powerParam{
+	{1294, "最大功率(kW)", func(p *Powertrain, v int) { p.EngineKw = v }},
+	{1295, "最大扭矩(N·m)", func(p *Powertrain, v int) { p.EngineTorqueNm = v }},
+	{1468, "电动机总功率(kW)", func(p *Powertrain, v int) { p.MotorKw = v }},
+	{1469, "电动机总扭矩(N·m)", func(p *Powertrain, v int) { p.MotorTorqueNm = v }},
+	{1470, "前电动机最大功率(kW)", func(p *Powertrain, v int) { p.FrontMotorKw = v }},
+	{1472, "后电动机最大功率(kW)", func(p *Powertrain, v int) { p.RearMotorKw = v }},
+	{1556, "系统综合功率(kW)", func(p *Powertrain, v int) { p.CombinedKw = v }},
+	{1557, "系统综合扭矩(N·m)", func(p *Powertrain, v int) { p.CombinedTorque = v }},
+}
+
+// set fills the powertrain from the spec param, false if the param is not about power or torque
+func (p *Powertrain) set(param ParamItem) bool {
+	for _, known := range powerParams {
+		// ids are checked by the unit in case che168 reuses them
+		byID := param.ID == known.id && strings.Contains(param.Name, unitOf(known.name))
+		if !byID && param.Name != known.name {
+			continue
+		}
+		if v, ok := paramNumber(param.Value); ok {
+			known.set(p, v)
+		}
+		return true
+	}
+	return false
+}
+
+// unitOf returns the unit in brackets at the end of the param name
+func unitOf(name string) string {
+	if idx := strings.LastIndex(name, "("); idx != -1 {
+		return name[idx:]
+	}
+	return name
+}
+
+// paramNumber reads a number at the start of values like "150", "110.5" or "150(204Ps)".
+// "-" and empty values mean that the car has no such part.
+func paramNumber(value string) (int, bool) {
+	value = strings.TrimSpace(value)
+	end := strings.IndexFunc(value, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+	if end != -1 {
+		value = value[:end]
+	}
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil || v <= 0 {
+		return 0, false
+	}
+	return int(math.Round(v)), true
+}
+
+// Motors returns power of all electric motors
+func (p Powertrain) Motors() int {
+	if p.MotorKw > 0 {
+		return p.MotorKw
+	}
+	return p.FrontMotorKw + p.RearMotorKw
+}
+
+// Hybrid tells whether the car has both the engine and electric motors
+func (p Powertrain) Hybrid() bool {
+	return p.EngineKw > 0 && p.Motors() > 0
+}
+
+// Total returns power of the car: combined power of hybrids if che168 knows it,
+// otherwise the engine and motors together
+func (p Powertrain) Total() int {
+	if p.CombinedKw > 0 {
+		return p.CombinedKw
+	}
+	return p.EngineKw + p.Motors()
+}
+
+// SetPower sets power of the car in kilowatts and horsepower
+func (ci *CarInfo) SetPower(kw int) {
+	ci.Power = kw
+	ci.PowerHp = KwToHp(kw)
+}
@@ -0,0 +1,54 @@
+package parser
+
+import "testing"
+
+func TestPowertrain(t *testing.T) {
+	params := []ParamItem{
+		{ID: 1294, Name: "最大功率(kW)", Value: "110"},
+		{ID: 1295, Name: "最大扭矩(N·m)", Value: "230"},
+		{ID: 1290, Name: "最大马力(Ps)", Value: "150"},
+		{ID: 1470, Name: "前电动机最大功率(kW)", Value: "145.5"},
+		{Name: "后电动机最大功率(kW)", Value: "-"},
+		{ID: 1556, Name: "系统综合功率(kW)", Value: "235(320Ps)"},
+		// a known id with another unit is not power
+		{ID: 1468, Name: "电池能量(kWh)", Value: "18.3"},
+	}
+
+	var p Powertrain
+	for _, param := range params {
+		p.set(param)
+	}
+
+	want := Powertrain{EngineKw: 110, EngineTorqueNm: 230, FrontMotorKw: 146, CombinedKw: 235}
+	if p != want {
+		t.Errorf("expected %+v, got %+v", want, p)
+	}
+	if !p.Hybrid() || p.Motors() != 146 || p.Total() != 235 {
+		t.Errorf("unexpected hybrid %v, motors %d, total %d", p.Hybrid(), p.Motors(), p.Total())
+	}
+
+	p.CombinedKw = 0
+	if p.Total() != 256 {
+		t.Errorf("without combined power the engine and motors are summed, got %d", p.Total())
+	}
+	if ev := (Powertrain{FrontMotorKw: 150, RearMotorKw: 200}); ev.Hybrid() || ev.Total() != 350 {
+		t.Errorf("unexpected electric car total %d", ev.Total())
+	}
+}
+
+func TestHorsepower(t *testing.T) {
+	if hp := KwToHp(110); hp != 149.6 {
+		t.Errorf("expected 149.6 hp, got %v", hp)
+	}
+	if kw := HpToKw(204); kw != 150 {
+		t.Errorf("expected 150 kW, got %d", kw)
+	}
+
+	var ci CarInfo
+	if err := ci.SetField(FieldPower, "204hp"); err != nil || ci.Power != 150 || ci.PowerHp != 203.9 {
+		t.Errorf("unexpected power %d kW, %v hp: %v", ci.Power, ci.PowerHp, err)
+	}
+	if err := ci.SetField(FieldPower, "много hp"); err == nil {
+		t.Errorf("expected error for invalid horsepower")
+	}
+}
@@ -0,0 +1,218 @@
+package parser
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Field names a CarInfo field the same way as JSON
+type Field string
+
+const (
+	FieldName       Field = "full_name"
+	FieldMileage    Field = "mileage"
+	FieldYear       Field = "year"
+	FieldPrice      Field = "price"
+	FieldPower      Field = "power"
+	FieldEngineSize Field = "engine_size"
+	FieldDrive      Field = "drive"
+	FieldFuelType   Field = "fuel_type"
+)
+
+// Fields lists the checked fields in the order they are shown
+var Fields = []Field{FieldPrice, FieldYear, FieldEngineSize, FieldPower, FieldMileage, FieldFuelType, FieldDrive, FieldName}
+
+// Source tells where the value of a field came from
+type Source string
+
+const (
+	SourceScraped    Source = "scraped"    // со страницы che168 как есть
+	SourceTranslated Source = "translated" // со страницы che168, переведено
+	SourceUser       Source = "user"       // введено пользователем
+	SourceDefault    Source = "default"    // подставлено значение по умолчанию
+)
+
+// IssueKind is a data-quality problem of a field
+type IssueKind string
+
+const (
+	IssueMissing    IssueKind = "missing"    // значение не найдено
+	IssueSuspicious IssueKind = "suspicious" // значение вне разумных пределов
+)
+
+// Issue is a data-quality problem of a field
+type Issue struct {
+	Field Field     `json:"field"`
+	Kind  IssueKind `json:"kind"`
+}
+
+// reasonable limits of values, everything outside is most likely a parsing error
+const (
+	minPrice      = 5_000 // в юанях
+	maxPrice      = 20_000_000
+	minEngineSize = 600 // в см³, 0 у электромобилей
+	maxEngineSize = 8_500
+	minPower      = 20 // в кВт
+	maxPower      = 1_200
+	minYear       = 1980
+	maxMileage    = 1_000_000 // в километрах
+)
+
+// SetSource marks the field as present with the value from the source
+func (ci *CarInfo) SetSource(f Field, s Source) {
+	if ci.Sources == nil {
+		// keeping fields of a car without sources present
+		sources := make(map[Field]Source)
+		for _, field := range Fields {
+			if source, ok := ci.Source(field); ok {
+				sources[field] = source
+			}
+		}
+		ci.Sources = sources
+	}
+	ci.Sources[f] = s
+}
+
+// Source returns where the field came from, false if the value is missing.
+// Cars without sources, entered by hand or saved before sources were kept,
+// have every non-zero field.
+func (ci CarInfo) Source(f Field) (Source, bool) {
+	if ci.Sources == nil {
+		if ci.nonZero(f) {
+			return SourceUser, true
+		}
+		return "", false
+	}
+	s, ok := ci.Sources[f]
+	return s, ok
+}
+
+// Has tells whether the field has a value
+func (ci CarInfo) Has(f Field) bool {
+	_, ok := ci.Source(f)
+	return ok
+}
+
+func (ci CarInfo) nonZero(f Field) bool {
+	switch f {
+	case FieldName:
+		return ci.FullName != ""
+	case FieldMileage:
+		return ci.Mileage.Known()
+	case FieldYear:
+		return ci.Year != ""
+	case FieldPrice:
+		return ci.Price > 0
+	case FieldPower:
+		return ci.Power > 0
+	case FieldEngineSize:
+		return ci.EngineSize > 0
+	case FieldDrive:
+		return ci.Drive != ""
+	case FieldFuelType:
+		return ci.FuelType != ""
+	}
+	return false
+}
+
+// Validate lists missing fields and fields with values outside of reasonable limits
+func (ci CarInfo) Validate() []Issue {
+	var issues []Issue
+	for _, f := range Fields {
+		switch {
+		case !ci.Has(f):
+			issues = append(issues, Issue{Field: f, Kind: IssueMissing})
+		case ci.suspicious(f):
+			issues = append(issues, Issue{Field: f, Kind: IssueSuspicious})
+		}
+	}
+	return issues
+}
+
+func (ci CarInfo) suspicious(f Field) bool {
+	switch f {
+	case FieldPrice:
+		return ci.Price < minPrice || ci.Price > maxPrice
+	case FieldEngineSize:
+		// electric cars have no engine
+		return ci.EngineSize != 0 && (ci.EngineSize < minEngineSize || ci.EngineSize > maxEngineSize)
+	case FieldPower:
+		return ci.Power < minPower || ci.Power > maxPower
+	case FieldYear:
+		year, err := strconv.Atoi(strings.SplitN(ci.Year, "-", 2)[0])
+		// not registered cars have a text instead of the year
+		return err == nil && (year < minYear || year > time.Now().Year())
+	case FieldMileage:
+		return ci.Mileage.Km > maxMileage
+	}
+	return false
+}
+
+// SetField sets the field from text entered by the user:
+// price in yuan, year as 2021 or 2021-05, engine size in cm³, power in kW or 204hp, mileage in km.
+func (ci *CarInfo) SetField(f Field, value string) error {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return fmt.Errorf("empty value of %s", f)
+	}
+
+	switch f {
+	case FieldPrice:
+		price, err := strconv.ParseFloat(value, 64)
+		if err != nil || price <= 0 {
+			return fmt.Errorf("invalid price %q", value)
+		}
+		ci.Price = price
+	case FieldYear:
+		year, month, hasMonth := strings.Cut(value, "-")
+		y, err := strconv.Atoi(year)
+		if err != nil || y < minYear || y > time.Now().Year() {
+			return fmt.Errorf("invalid year %q", value)
+		}
+		m := 1
+		if hasMonth {
+			if m, err = strconv.Atoi(month); err != nil || m < 1 || m > 12 {
+				return fmt.Errorf("invalid month %q", value)
+			}
+		}
+		ci.Year = fmt.Sprintf("%d-%02d", y, m)
+	case FieldEngineSize:
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid engine size %q", value)
+		}
+		ci.EngineSize = n
+	case FieldPower:
+		// power in horsepower ends with hp
+		hp, isHp := strings.CutSuffix(strings.ToLower(value), "hp")
+		n, err := strconv.ParseFloat(strings.TrimSpace(hp), 64)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid power %q", value)
+		}
+		if isHp {
+			ci.SetPower(HpToKw(n))
+		} else {
+			ci.SetPower(int(math.Round(n)))
+		}
+	case FieldMileage:
+		km, err := strconv.Atoi(value)
+		if err != nil || km < 0 {
+			return fmt.Errorf("invalid mileage %q", value)
+		}
+		ci.Mileage = Mileage{Km: km, Unit: UnitKm, Confidence: ConfidenceExact, Raw: value}
+	case FieldFuelType:
+		ci.FuelType = value
+	case FieldDrive:
+		ci.Drive = value
+	case FieldName:
+		ci.FullName = value
+	default:
+		return fmt.Errorf("unknown field %q", f)
+	}
+
+	ci.SetSource(f, SourceUser)
+	return nil
+}
@@ -0,0 +1,75 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	ci := CarInfo{Price: 150_000, Year: "2021-05", EngineSize: 50, Power: 140}
+	ci.SetSource(FieldFuelType, SourceTranslated)
+	ci.FuelType = "Бензин"
+
+	want := []Issue{
+		{FieldEngineSize, IssueSuspicious},
+		{FieldMileage, IssueMissing},
+		{FieldDrive, IssueMissing},
+		{FieldName, IssueMissing},
+	}
+	if issues := ci.Validate(); !reflect.DeepEqual(issues, want) {
+		t.Errorf("expected %v, got %v", want, issues)
+	}
+
+	// electric cars have no engine
+	ci.EngineSize = 0
+	if issues := ci.Validate(); issues[0] != (Issue{FieldMileage, IssueMissing}) {
+		t.Errorf("zero engine size of a present field is not suspicious: %v", issues)
+	}
+}
+
+func TestSource(t *testing.T) {
+	// cars saved before sources were kept
+	legacy := CarInfo{Price: 100_000}
+	if s, ok := legacy.Source(FieldPrice); !ok || s != SourceUser {
+		t.Errorf("expected price entered by the user, got %q %v", s, ok)
+	}
+	if legacy.Has(FieldYear) {
+		t.Errorf("empty year should be missing")
+	}
+
+	legacy.SetSource(FieldYear, SourceScraped)
+	if !legacy.Has(FieldPrice) || !legacy.Has(FieldYear) || legacy.Has(FieldPower) {
+		t.Errorf("unexpected sources %v", legacy.Sources)
+	}
+}
+
+func TestSetField(t *testing.T) {
+	var ci CarInfo
+	tests := []struct {
+		field Field
+		value string
+		ok    bool
+	}{
+		{FieldPrice, "150000", true},
+		{FieldPrice, "-1", false},
+		{FieldYear, "2021", true},
+		{FieldYear, "2021-13", false},
+		{FieldYear, "1900", false},
+		{FieldEngineSize, "1998", true},
+		{FieldPower, "много", false},
+		{FieldMileage, "30000", true},
+		{FieldDrive, " ", false},
+	}
+	for _, tt := range tests {
+		if err := ci.SetField(tt.field, tt.value); (err == nil) != tt.ok {
+			t.Errorf("%s=%q: unexpected error %v", tt.field, tt.value, err)
+		}
+	}
+
+	if ci.Year != "2021-01" || ci.EngineSize != 1998 || ci.Mileage.Km != 30_000 || !ci.Mileage.Known() {
+		t.Errorf("unexpected car %+v", ci)
+	}
+	if s, _ := ci.Source(FieldYear); s != SourceUser || ci.Has(FieldPower) {
+		t.Errorf("unexpected sources %v", ci.Sources)
+	}
+}
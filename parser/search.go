@@ -0,0 +1,141 @@
+package parser
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Listing is a car card on che168 search pages
+type Listing struct {
+	CarId   string  `json:"car_id"`
+	URL     string  `json:"url"`
+	Name    string  `json:"name"`
+	Price   float64 `json:"price"`   // в юанях
+	Mileage float64 `json:"mileage"` // в километрах
+	Year    int     `json:"year"`    // 0 если машина не ставилась на учет
+}
+
+// SearchQuery selects che168 search pages.
+// Values are latin names from che168 addresses: beijing, aodi, aodia4l.
+// Zero filters are not limited.
+type SearchQuery struct {
+	City  string // пусто - вся страна
+	Brand string
+	Model string
+
+	SpecID string // только машины этой комплектации
+
+	PriceFrom  float64 // в юанях
+	PriceTo    float64
+	AgeFrom    int // возраст машины в годах
+	AgeTo      int
+	MaxMileage float64 // в километрах
+}
+
+// wan formats a value in tens of thousands the way che168 addresses have it
+func wan(v float64) string {
+	return strconv.FormatFloat(v/10_000, 'f', -1, 64)
+}
+
+// SearchURL returns the address of the search results page, pages start from 1.
+// Filters are put into the address like che168 does: price range in tens of thousands of yuan
+// as a separate part (10_25), age in years after "a" (a0_5), mileage in tens of thousands of km after "m" (m0_8).
+func SearchURL(q SearchQuery, page int) string {
+	city := q.City
+	if city == "" {
+		city = "china"
+	}
+
+	parts := []string{"https://www.che168.com", url.PathEscape(city)}
+	if q.Brand != "" {
+		parts = append(parts, url.PathEscape(q.Brand))
+		if q.Model != "" {
+			parts = append(parts, url.PathEscape(q.Model))
+		}
+	}
+	if q.PriceFrom > 0 || q.PriceTo > 0 {
+		parts = append(parts, wan(q.PriceFrom)+"_"+wan(q.PriceTo))
+	}
+
+	mileage := ""
+	if q.MaxMileage > 0 {
+		mileage = "0_" + wan(q.MaxMileage)
+	}
+	parts = append(parts, fmt.Sprintf("a%d_%dm%ssdgscncgpi1ltocsp%dexx0", q.AgeFrom, q.AgeTo, mileage, page))
+
+	address := strings.Join(parts, "/") + "/"
+	if q.SpecID != "" {
+		address += "?specid=" + url.QueryEscape(q.SpecID)
+	}
+	return address
+}
+
+// SearchListings gets car cards from one page of che168 search results
+func SearchListings(q SearchQuery, page int) ([]Listing, error) {
+	resp, err := makeRequest(SearchURL(q, page), 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %v", err)
+	}
+	return ParseListings(resp)
+}
+
+// ParseListings extracts car cards from the search results page
+func ParseListings(html string) ([]Listing, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return nil, fmt.Errorf("error while parsing html: %v", err)
+	}
+
+	var listings []Listing
+	doc.Find("li.cards-li[infoid]").Each(func(_ int, card *goquery.Selection) {
+		l := Listing{
+			CarId: card.AttrOr("infoid", ""),
+			Name:  strings.TrimSpace(card.AttrOr("carname", "")),
+		}
+		if l.CarId == "" {
+			return
+		}
+
+		// price and mileage are in tens of thousands
+		if price, err := strconv.ParseFloat(card.AttrOr("price", ""), 64); err == nil {
+			l.Price = price * 10_000
+		}
+		if mileage, err := strconv.ParseFloat(card.AttrOr("milage", ""), 64); err == nil {
+			l.Mileage = mileage * 10_000
+		}
+
+		// registration date looks like 2021/5
+		regDate := card.AttrOr("regdate", "")
+		if idx := strings.Index(regDate, "/"); idx != -1 {
+			regDate = regDate[:idx]
+		}
+		l.Year, _ = strconv.Atoi(regDate)
+
+		l.URL = listingURL(card.Find("a.carinfo").AttrOr("href", ""), card.AttrOr("dealerid", ""), l.CarId)
+		listings = append(listings, l)
+	})
+	return listings, nil
+}
+
+// listingURL makes an absolute address of the listing without tracking parameters
+func listingURL(href, dealerID, carID string) string {
+	if idx := strings.IndexAny(href, "?#"); idx != -1 {
+		href = href[:idx]
+	}
+
+	switch {
+	case strings.HasPrefix(href, "//"):
+		return "https:" + href
+	case strings.HasPrefix(href, "/"):
+		return "https://www.che168.com" + href
+	case strings.HasPrefix(href, "http"):
+		return href
+	case dealerID != "":
+		return fmt.Sprintf("https://www.che168.com/dealer/%s/%s.html", dealerID, carID)
+	}
+	return fmt.Sprintf("https://www.che168.com/personal/%s.html", carID)
+}
@@ -0,0 +1,98 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// SellerType tells who sells the car
+type SellerType string
+
+const (
+	SellerDealer  SellerType = "dealer"
+	SellerPrivate SellerType = "private"
+)
+
+// Seller is who sells the car and where
+type Seller struct {
+	Type      SellerType `json:"type,omitempty"`
+	DealerID  string     `json:"dealer_id,omitempty"`
+	Name      string     `json:"name,omitempty"`
+	City      string     `json:"city,omitempty"`      // как на che168: 北京, используется для расчета доставки
+	Province  string     `json:"province,omitempty"`  // 北京, 广东
+	Published string     `json:"published,omitempty"` // дата публикации: 2025-05-01
+	Badges    []string   `json:"badges,omitempty"`    // коды знаков che168: certified, warranty
+}
+
+// known che168 certification badges by a part of their title
+var badges = []struct {
+	title string
+	code  string
+}{
+	{"认证", "certified"},    // 认证车, 品牌认证
+	{"质保", "warranty"},     // 延长质保
+	{"无理由", "return"},      // 7天无理由退车
+	{"检测", "inspected"},    // 专业检测
+	{"保障", "guarantee"},    // 商家保障
+	{"原厂", "manufacturer"}, // 原厂认证二手车
+}
+
+var dealerURLRegexp = regexp.MustCompile(`/dealer/(\d+)/`)
+
+// DealerIDFromURL returns the dealer id from listing addresses like /dealer/123/456.html
+func DealerIDFromURL(url string) string {
+	if m := dealerURLRegexp.FindStringSubmatch(url); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// parseSeller collects the seller of the listing from the CarConfig page
+func parseSeller(doc *goquery.Document, url string) Seller {
+	value := func(selector string) string {
+		return strings.TrimSpace(doc.Find(selector).AttrOr("value", ""))
+	}
+
+	s := Seller{
+		DealerID:  value("#car_dealerid"),
+		Name:      value("#car_dealername"),
+		City:      strings.TrimSuffix(value("#car_cityname"), "市"),
+		Province:  strings.TrimSuffix(strings.TrimSuffix(value("#car_provincename"), "省"), "市"),
+		Published: value("#car_publicdate"),
+	}
+	if s.DealerID == "0" {
+		s.DealerID = ""
+	}
+	if s.DealerID == "" {
+		s.DealerID = DealerIDFromURL(url)
+	}
+
+	s.Type = SellerPrivate
+	if s.DealerID != "" {
+		s.Type = SellerDealer
+	}
+
+	seen := make(map[string]bool)
+	doc.Find(".car-tags span, .tags-list li, .car-box .tag").Each(func(_ int, tag *goquery.Selection) {
+		title := strings.TrimSpace(tag.Text())
+		for _, b := range badges {
+			if strings.Contains(title, b.title) && !seen[b.code] {
+				seen[b.code] = true
+				s.Badges = append(s.Badges, b.code)
+				break
+			}
+		}
+	})
+	return s
+}
+
+// CarIDFromURL returns the car id of the listing address, empty if there is none
+func CarIDFromURL(url string) string {
+	id, err := getCarId(url)
+	if err != nil {
+		return ""
+	}
+	return id
+}
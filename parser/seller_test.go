@@ -0,0 +1,49 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+const sellerHTML = `<html><body>
+<input type="hidden" id="car_dealerid" value="12345">
+<input type="hidden" id="car_dealername" value=" 北京优信车行 ">
+<input type="hidden" id="car_cityname" value="北京市">
+<input type="hidden" id="car_provincename" value="北京">
+<input type="hidden" id="car_publicdate" value="2025-05-01">
+<div class="car-tags"><span>品牌认证</span><span>延长质保</span><span>认证车</span><span>急售</span></div>
+</body></html>`
+
+func TestParseSeller(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(sellerHTML))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := parseSeller(doc, "https://www.che168.com/dealer/12345/56789.html")
+	if s.Type != SellerDealer || s.DealerID != "12345" || s.Name != "北京优信车行" {
+		t.Errorf("unexpected dealer %+v", s)
+	}
+	if s.City != "北京" || s.Province != "北京" || s.Published != "2025-05-01" {
+		t.Errorf("unexpected location %+v", s)
+	}
+	if strings.Join(s.Badges, ",") != "certified,warranty" {
+		t.Errorf("unexpected badges %v", s.Badges)
+	}
+
+	doc, _ = goquery.NewDocumentFromReader(strings.NewReader(`<input id="car_dealerid" value="0"><input id="car_cityname" value="广州">`))
+	if s := parseSeller(doc, "https://www.che168.com/personal/56789.html"); s.Type != SellerPrivate || s.DealerID != "" || s.City != "广州" {
+		t.Errorf("unexpected private seller %+v", s)
+	}
+
+	// the dealer id of old pages is only in the address
+	doc, _ = goquery.NewDocumentFromReader(strings.NewReader(`<html></html>`))
+	if s := parseSeller(doc, "https://www.che168.com/dealer/777/56789.html"); s.Type != SellerDealer || s.DealerID != "777" {
+		t.Errorf("unexpected seller from url %+v", s)
+	}
+	if CarIDFromURL("https://www.che168.com/dealer/777/56789.html") != "56789" || CarIDFromURL("https://www.che168.com/") != "" {
+		t.Errorf("unexpected car ids")
+	}
+}
@@ -1,18 +1,25 @@
 package parser
 
 type CarInfo struct {
-	FullName   string
-	Milage     string
-	Year       string
-	Price      float64
-	Power      int
-	EngineSize int
-	Drive      string
-	FuelType   string
-	SpecID     string
-	CarId      string
-}
+	FullName   string   `json:"full_name"`
+	Mileage    Mileage  `json:"mileage"`
+	Year       string   `json:"year"`
+	Price      float64  `json:"price"`
+	Power      int      `json:"power"`    // в кВт, у гибридов системная
+	PowerHp    float64  `json:"power_hp"` // в л.с.
+	EngineSize int      `json:"engine_size"`
+	Drive      string   `json:"drive"`
+	FuelType   string   `json:"fuel_type"`
+	SpecID     string   `json:"spec_id"`
+	CarId      string   `json:"car_id"`
+	Photos     []string `json:"photos,omitempty"` // фото в исходном размере
+	Seller     Seller   `json:"seller"`
+
+	Powertrain Powertrain `json:"powertrain"`
 
+	// откуда взяты значения, поля без источника не найдены
+	Sources map[Field]Source `json:"sources,omitempty"`
+}
 
 // For parsing car specs
 type SpecResponse struct {
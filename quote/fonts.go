@@ -0,0 +1,13 @@
+package quote
+
+import _ "embed"
+
+// DejaVu Sans has letters of every language of the bot, Kazakh and Kyrgyz ones included.
+// The license is in fonts/LICENSE.
+var (
+	//go:embed fonts/DejaVuSansCondensed.ttf
+	regularTTF []byte
+
+	//go:embed fonts/DejaVuSansCondensed-Bold.ttf
+	boldTTF []byte
+)
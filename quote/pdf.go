@@ -0,0 +1,101 @@
+package quote
+
+import (
+	"bytes"
+	"fmt"
+	"mashinki/taxes"
+	"net/http"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// PDF renders the quote as an A4 document
+func (q Quote) PDF() ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.AddUTF8FontFromBytes("go", "", regularTTF)
+	pdf.AddUTF8FontFromBytes("go", "B", boldTTF)
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.AddPage()
+
+	pageWidth, _ := pdf.GetPageSize()
+	width := pageWidth - 30
+
+	// Header
+	pdf.SetFont("go", "B", 20)
+	pdf.SetTextColor(200, 30, 30)
+	pdf.CellFormat(width/2, 10, q.Brand, "", 0, "L", false, 0, "")
+	pdf.SetFont("go", "", 10)
+	pdf.SetTextColor(100, 100, 100)
+	pdf.CellFormat(width/2, 10, q.date(), "", 1, "R", false, 0, "")
+	pdf.SetDrawColor(200, 30, 30)
+	pdf.Line(15, pdf.GetY(), 15+width, pdf.GetY())
+	pdf.Ln(4)
+
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetFont("go", "", 12)
+	pdf.CellFormat(width, 7, q.t("quote.title"), "", 1, "L", false, 0, "")
+	pdf.SetFont("go", "B", 16)
+	pdf.MultiCell(width, 8, q.title(), "", "L", false)
+	pdf.Ln(2)
+
+	// Photo
+	if len(q.Photo) > 0 {
+		imageType := "JPG"
+		if http.DetectContentType(q.Photo) == "image/png" {
+			imageType = "PNG"
+		}
+		info := pdf.RegisterImageOptionsReader("photo", fpdf.ImageOptions{ImageType: imageType}, bytes.NewReader(q.Photo))
+		if pdf.Ok() && info != nil {
+			height := width / 2
+			pdf.ImageOptions("photo", 15, pdf.GetY(), 0, height, false, fpdf.ImageOptions{ImageType: imageType}, 0, "")
+			pdf.Ln(height + 4)
+		} else {
+			// a broken photo should not break the whole quote
+			pdf.ClearError()
+		}
+	}
+
+	// Specs
+	pdf.SetFont("go", "B", 12)
+	pdf.CellFormat(width, 8, q.t("result.specs"), "", 1, "L", false, 0, "")
+	pdf.SetFont("go", "", 10)
+	for _, f := range q.specs() {
+		pdf.CellFormat(width*0.35, 6, f.label, "", 0, "L", false, 0, "")
+		pdf.CellFormat(width*0.65, 6, f.value, "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	// Payments
+	pdf.SetFont("go", "B", 12)
+	pdf.CellFormat(width, 8, q.t("quote.calculation"), "", 1, "L", false, 0, "")
+	pdf.SetFont("go", "B", 10)
+	pdf.SetFillColor(240, 240, 240)
+	pdf.CellFormat(width*0.35, 7, q.t("quote.col_item"), "1", 0, "L", true, 0, "")
+	pdf.CellFormat(width*0.40, 7, q.t("quote.col_note"), "1", 0, "L", true, 0, "")
+	pdf.CellFormat(width*0.25, 7, q.t("quote.col_amount"), "1", 1, "R", true, 0, "")
+	pdf.SetFont("go", "", 10)
+	for _, item := range q.Result.Items {
+		pdf.CellFormat(width*0.35, 7, item.Name, "1", 0, "L", false, 0, "")
+		pdf.CellFormat(width*0.40, 7, q.note(item.Note), "1", 0, "L", false, 0, "")
+		pdf.CellFormat(width*0.25, 7, taxes.Grouped.Format(item.Amount), "1", 1, "R", false, 0, "")
+	}
+	pdf.SetFont("go", "B", 11)
+	pdf.CellFormat(width*0.75, 8, q.t("quote.total"), "1", 0, "L", true, 0, "")
+	pdf.CellFormat(width*0.25, 8, taxes.Grouped.Format(q.Result.Total), "1", 1, "R", true, 0, "")
+	pdf.Ln(3)
+
+	pdf.SetFont("go", "", 9)
+	pdf.MultiCell(width, 5, q.rates(), "", "L", false)
+	pdf.Ln(3)
+
+	pdf.SetTextColor(120, 120, 120)
+	pdf.SetFont("go", "", 8)
+	pdf.MultiCell(width, 4, q.t("quote.disclaimer"), "", "L", false)
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("error while generating pdf: %v", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,186 @@
+package quote
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg"
+	"image/png"
+	"mashinki/taxes"
+	"strings"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	pngWidth   = 1000
+	pngPadding = 40
+)
+
+var (
+	colorBrand = color.RGBA{200, 30, 30, 255}
+	colorText  = color.RGBA{0, 0, 0, 255}
+	colorMuted = color.RGBA{120, 120, 120, 255}
+	colorFill  = color.RGBA{240, 240, 240, 255}
+)
+
+// canvas draws text lines from top to bottom
+type canvas struct {
+	img *image.RGBA
+	y   int
+}
+
+func newFace(ttf []byte, size float64) (font.Face, error) {
+	f, err := opentype.Parse(ttf)
+	if err != nil {
+		return nil, err
+	}
+	return opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+}
+
+func (c *canvas) text(x int, s string, face font.Face, col color.Color) {
+	d := font.Drawer{
+		Dst:  c.img,
+		Src:  image.NewUniform(col),
+		Face: face,
+		Dot:  fixed.P(x, c.y+face.Metrics().Ascent.Ceil()),
+	}
+	d.DrawString(s)
+}
+
+// textRight draws s aligned to the right edge at x
+func (c *canvas) textRight(x int, s string, face font.Face, col color.Color) {
+	c.text(x-font.MeasureString(face, s).Ceil(), s, face, col)
+}
+
+func (c *canvas) line(face font.Face) {
+	c.y += face.Metrics().Height.Ceil() + 6
+}
+
+func (c *canvas) fill(y, height int, col color.Color) {
+	draw.Draw(c.img, image.Rect(pngPadding, y, pngWidth-pngPadding, y+height), image.NewUniform(col), image.Point{}, draw.Src)
+}
+
+// wrap splits s into lines fitting into width
+func wrap(s string, face font.Face, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(s) {
+		candidate := strings.TrimSpace(line + " " + word)
+		if line != "" && font.MeasureString(face, candidate).Ceil() > width {
+			lines = append(lines, line)
+			candidate = word
+		}
+		line = candidate
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// PNG renders the quote as an image
+func (q Quote) PNG() ([]byte, error) {
+	regular, err := newFace(regularTTF, 20)
+	if err != nil {
+		return nil, fmt.Errorf("error while loading font: %v", err)
+	}
+	small, err := newFace(regularTTF, 15)
+	if err != nil {
+		return nil, fmt.Errorf("error while loading font: %v", err)
+	}
+	bold, err := newFace(boldTTF, 22)
+	if err != nil {
+		return nil, fmt.Errorf("error while loading font: %v", err)
+	}
+	title, err := newFace(boldTTF, 34)
+	if err != nil {
+		return nil, fmt.Errorf("error while loading font: %v", err)
+	}
+
+	contentWidth := pngWidth - 2*pngPadding
+
+	var photo image.Image
+	photoHeight := 0
+	if len(q.Photo) > 0 {
+		// a broken photo should not break the whole quote
+		if img, _, err := image.Decode(bytes.NewReader(q.Photo)); err == nil {
+			photo = img
+			b := img.Bounds()
+			photoHeight = contentWidth * b.Dy() / b.Dx()
+		}
+	}
+
+	height := pngPadding*2 + 300 + photoHeight +
+		(len(q.specs())+len(q.Result.Items)+len(wrap(q.t("quote.disclaimer"), small, contentWidth))+8)*(regular.Metrics().Height.Ceil()+8)
+
+	c := &canvas{img: image.NewRGBA(image.Rect(0, 0, pngWidth, height)), y: pngPadding}
+	draw.Draw(c.img, c.img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	// Header
+	c.text(pngPadding, q.Brand, title, colorBrand)
+	c.textRight(pngWidth-pngPadding, q.date(), regular, colorMuted)
+	c.line(title)
+	c.fill(c.y, 3, colorBrand)
+	c.y += 15
+
+	c.text(pngPadding, q.t("quote.title"), regular, colorText)
+	c.line(regular)
+	for _, l := range wrap(q.title(), bold, contentWidth) {
+		c.text(pngPadding, l, bold, colorText)
+		c.line(bold)
+	}
+	c.y += 10
+
+	// Photo
+	if photo != nil {
+		rect := image.Rect(pngPadding, c.y, pngWidth-pngPadding, c.y+photoHeight)
+		draw.CatmullRom.Scale(c.img, rect, photo, photo.Bounds(), draw.Over, nil)
+		c.y += photoHeight + 15
+	}
+
+	// Specs
+	for _, f := range q.specs() {
+		c.text(pngPadding, f.label, regular, colorMuted)
+		c.text(pngPadding+contentWidth*35/100, f.value, regular, colorText)
+		c.line(regular)
+	}
+	c.y += 15
+
+	// Payments
+	for _, item := range q.Result.Items {
+		c.text(pngPadding, item.Name, regular, colorText)
+		c.text(pngPadding+contentWidth*35/100, q.note(item.Note), small, colorMuted)
+		c.textRight(pngWidth-pngPadding, taxes.Grouped.Format(item.Amount)+" "+q.t("unit.rub"), regular, colorText)
+		c.line(regular)
+	}
+	c.fill(c.y, bold.Metrics().Height.Ceil()+12, colorFill)
+	c.y += 6
+	c.text(pngPadding+10, q.t("quote.total"), bold, colorText)
+	c.textRight(pngWidth-pngPadding-10, taxes.Grouped.Format(q.Result.Total)+" "+q.t("unit.rub"), bold, colorBrand)
+	c.line(bold)
+	c.y += 15
+
+	for _, l := range wrap(q.rates(), small, contentWidth) {
+		c.text(pngPadding, l, small, colorText)
+		c.line(small)
+	}
+	c.y += 10
+	for _, l := range wrap(q.t("quote.disclaimer"), small, contentWidth) {
+		c.text(pngPadding, l, small, colorMuted)
+		c.line(small)
+	}
+
+	// Cutting unused space
+	img := c.img.SubImage(image.Rect(0, 0, pngWidth, min(height, c.y+pngPadding)))
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("error while encoding png: %v", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,78 @@
+// Package quote renders calculation results as commercial offers for customers
+package quote
+
+import (
+	"fmt"
+	"mashinki/i18n"
+	"mashinki/parser"
+	"mashinki/taxes"
+	"strings"
+	"time"
+)
+
+// Quote is a commercial offer for one car
+type Quote struct {
+	Result taxes.Result
+	Photo  []byte // JPEG or PNG, optional
+	Brand  string
+	Date   time.Time
+	Lang   i18n.Lang
+}
+
+// New creates a quote in the language dated now
+func New(result taxes.Result, brand string, lang i18n.Lang) Quote {
+	if brand == "" {
+		brand = "Mashinki"
+	}
+	return Quote{
+		Result: result.Localize(lang),
+		Brand:  brand,
+		Date:   time.Now(),
+		Lang:   lang,
+	}
+}
+
+// t translates a label of the quote
+func (q Quote) t(key string, args ...interface{}) string {
+	return i18n.T(q.Lang, key, args...)
+}
+
+// field is a label and value pair of the quote
+type field struct {
+	label string
+	value string
+}
+
+func (q Quote) title() string {
+	if q.Result.Car.FullName != "" {
+		return q.Result.Car.FullName
+	}
+	return q.t("quote.default_car")
+}
+
+func (q Quote) specs() []field {
+	car := q.Result.Car
+	return []field{
+		{q.t("result.year"), car.Year},
+		{q.t("result.mileage"), car.Mileage.Format(q.t("unit.km"))},
+		{q.t("result.engine"), fmt.Sprintf("%d %s", car.EngineSize, q.t("unit.cc"))},
+		{q.t("result.power"), fmt.Sprintf("%d %s (%.0f %s)", car.Power, q.t("unit.kw_long"), parser.KwToHp(car.Power), q.t("unit.hp"))},
+		{q.t("result.drive"), car.Drive},
+		{q.t("result.fuel"), car.FuelType},
+		{q.t("quote.price_china"), taxes.Grouped.Format(car.Price) + " ¥"},
+		{q.t("result.band"), q.Result.TaxBand},
+	}
+}
+
+func (q Quote) rates() string {
+	return q.t("quote.rates", fmt.Sprintf("%g", q.Result.Rates.CNY), fmt.Sprintf("%g", q.Result.Rates.EUR), q.Result.ProfileTitle)
+}
+
+func (q Quote) date() string {
+	return q.Date.Format("02.01.2006")
+}
+
+// note returns the tariff note of an item with rubles written the same way as amounts
+func (q Quote) note(s string) string {
+	return strings.ReplaceAll(s, "₽", q.t("unit.rub"))
+}
@@ -0,0 +1,85 @@
+package quote
+
+import (
+	"bytes"
+	"image/png"
+	"mashinki/i18n"
+	"mashinki/parser"
+	"mashinki/taxes"
+	"strings"
+	"testing"
+	"unicode"
+
+	"golang.org/x/image/font/sfnt"
+)
+
+func testQuote() Quote {
+	return New(taxes.Calculate(parser.CarInfo{
+		FullName:   "Тестовая машина",
+		Year:       "2021-05",
+		Price:      150_000,
+		EngineSize: 1998,
+	}, ""), "", i18n.Russian)
+}
+
+func TestPDF(t *testing.T) {
+	data, err := testQuote().PDF()
+	if err != nil {
+		t.Fatalf("error while generating pdf: %v", err)
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF")) {
+		t.Errorf("output is not a pdf")
+	}
+}
+
+func TestPNG(t *testing.T) {
+	q := testQuote()
+	q.Photo = []byte("broken photo")
+
+	data, err := q.PNG()
+	if err != nil {
+		t.Fatalf("error while generating png: %v", err)
+	}
+	if _, err := png.Decode(bytes.NewReader(data)); err != nil {
+		t.Errorf("output is not a png: %v", err)
+	}
+}
+
+func TestLanguage(t *testing.T) {
+	r := testQuote().Result
+
+	q := New(r, "", i18n.English)
+	if q.Lang != i18n.English || q.Result.Items[1].Name != "Customs duty" {
+		t.Errorf("quote is not in english: %v %q", q.Lang, q.Result.Items[1].Name)
+	}
+	if q.note("20 000 ₽") != "20 000 RUB" {
+		t.Errorf("unexpected note: %q", q.note("20 000 ₽"))
+	}
+
+	if q := New(r, "", i18n.Kazakh); q.Lang != i18n.Kazakh {
+		t.Errorf("expected kazakh quote, got %v", q.Lang)
+	}
+}
+
+func TestFontLetters(t *testing.T) {
+	for _, ttf := range [][]byte{regularTTF, boldTTF} {
+		f, err := sfnt.Parse(ttf)
+		if err != nil {
+			t.Fatalf("error while parsing font: %v", err)
+		}
+
+		var buf sfnt.Buffer
+		for _, lang := range i18n.Languages {
+			q := New(testQuote().Result, "", lang)
+			texts := []string{q.t("quote.title"), q.t("quote.disclaimer"), q.t("quote.total"), q.rates(), q.Result.TaxBand}
+			for _, item := range q.Result.Items {
+				texts = append(texts, item.Name, q.note(item.Note))
+			}
+			for _, r := range strings.Join(texts, "") {
+				if i, err := f.GlyphIndex(&buf, r); err != nil || (i == 0 && !unicode.IsSpace(r)) {
+					t.Errorf("%s: the font has no %q", lang, r)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+)
+
+// Queue limits the number of lookups running at the same time.
+// Waiting lookups are served in order of arrival.
+type Queue struct {
+	slots   chan struct{}
+	mu      sync.Mutex
+	waiting int
+}
+
+func NewQueue(workers int) *Queue {
+	return &Queue{slots: make(chan struct{}, workers)}
+}
+
+// Wait blocks until a worker is free. If all workers are busy,
+// onQueued is called with the position in the queue starting from 1.
+func (q *Queue) Wait(ctx context.Context, onQueued func(position int)) error {
+	select {
+	case q.slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	q.mu.Lock()
+	q.waiting++
+	position := q.waiting
+	q.mu.Unlock()
+
+	defer func() {
+		q.mu.Lock()
+		q.waiting--
+		q.mu.Unlock()
+	}()
+
+	if onQueued != nil {
+		onQueued(position)
+	}
+
+	select {
+	case q.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Done frees the worker taken by Wait
+func (q *Queue) Done() {
+	<-q.slots
+}
@@ -0,0 +1,182 @@
+// Package ratelimit protects the bot from users sending too many requests
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Config describes all limits
+type Config struct {
+	Rate          float64       // messages per second restored to the bucket
+	Burst         int           // bucket size
+	MaxConcurrent int           // lookups of one user at the same time
+	FloodMessages int           // messages within FloodWindow that lead to a ban, 0 disables bans
+	FloodWindow   time.Duration // window of flood detection
+	BanDuration   time.Duration // how long a flooding user is ignored
+}
+
+// DefaultConfig returns limits suitable for a public bot
+func DefaultConfig() Config {
+	return Config{
+		Rate:          0.5,
+		Burst:         5,
+		MaxConcurrent: 2,
+		FloodMessages: 20,
+		FloodWindow:   30 * time.Second,
+		BanDuration:   15 * time.Minute,
+	}
+}
+
+// Verdict is the result of checking a message
+type Verdict int
+
+const (
+	Allowed    Verdict = iota
+	Limited            // too many messages, the message should be skipped
+	Banned             // user is banned
+	JustBanned         // user has been banned by this message
+)
+
+type userLimits struct {
+	tokens      float64
+	last        time.Time
+	recent      []time.Time
+	active      int
+	bannedUntil time.Time
+}
+
+// pruneEvery is how often users without limits in effect are forgotten
+const pruneEvery = 10 * time.Minute
+
+// Limiter keeps per-user token buckets, flood detection and concurrent lookup counters
+type Limiter struct {
+	cfg       Config
+	mu        sync.Mutex
+	users     map[int64]*userLimits
+	now       func() time.Time
+	lastPrune time.Time
+}
+
+func NewLimiter(cfg Config) *Limiter {
+	return &Limiter{
+		cfg:   cfg,
+		users: make(map[int64]*userLimits),
+		now:   time.Now,
+	}
+}
+
+func (l *Limiter) user(userID int64) *userLimits {
+	u, exists := l.users[userID]
+	if !exists {
+		u = &userLimits{tokens: float64(l.cfg.Burst), last: l.now()}
+		l.users[userID] = u
+	}
+	return u
+}
+
+// Allow registers a message from the user and tells whether it should be processed
+func (l *Limiter) Allow(userID int64) Verdict {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastPrune) >= pruneEvery {
+		l.prune(now)
+	}
+	u := l.user(userID)
+
+	if now.Before(u.bannedUntil) {
+		return Banned
+	}
+
+	// Flood detection counts all messages, even the limited ones
+	cutoff := now.Add(-l.cfg.FloodWindow)
+	recent := u.recent[:0]
+	for _, t := range u.recent {
+		if t.After(cutoff) {
+			recent = append(recent, t)
+		}
+	}
+	u.recent = append(recent, now)
+	if l.cfg.FloodMessages > 0 && len(u.recent) >= l.cfg.FloodMessages {
+		u.bannedUntil = now.Add(l.cfg.BanDuration)
+		u.recent = nil
+		return JustBanned
+	}
+
+	// Token bucket
+	u.tokens += now.Sub(u.last).Seconds() * l.cfg.Rate
+	if u.tokens > float64(l.cfg.Burst) {
+		u.tokens = float64(l.cfg.Burst)
+	}
+	u.last = now
+
+	if u.tokens < 1 {
+		return Limited
+	}
+	u.tokens--
+	return Allowed
+}
+
+// prune forgets users whose limits are no longer in effect: no lookups, no ban,
+// no messages in the flood window and a full bucket. Such users start from scratch anyway.
+func (l *Limiter) prune(now time.Time) {
+	l.lastPrune = now
+	refill := time.Duration(0)
+	if l.cfg.Rate > 0 {
+		refill = time.Duration(float64(l.cfg.Burst) / l.cfg.Rate * float64(time.Second))
+	}
+	idle := max(refill, l.cfg.FloodWindow)
+
+	for id, u := range l.users {
+		if u.active == 0 && !now.Before(u.bannedUntil) && now.Sub(u.last) > idle {
+			delete(l.users, id)
+		}
+	}
+}
+
+// Acquire reserves a lookup for the user.
+// Returns false if the user already has MaxConcurrent lookups in progress.
+func (l *Limiter) Acquire(userID int64) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	u := l.user(userID)
+	if u.active >= l.cfg.MaxConcurrent {
+		return false
+	}
+	u.active++
+	return true
+}
+
+// Release frees the lookup reserved by Acquire
+func (l *Limiter) Release(userID int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if u := l.user(userID); u.active > 0 {
+		u.active--
+	}
+}
+
+// Ban makes the limiter ignore the user for duration, forever if duration is not positive
+func (l *Limiter) Ban(userID int64, duration time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if duration <= 0 {
+		duration = 100 * 365 * 24 * time.Hour
+	}
+	l.user(userID).bannedUntil = l.now().Add(duration)
+}
+
+// Unban lifts the ban of the user
+func (l *Limiter) Unban(userID int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	u := l.user(userID)
+	u.bannedUntil = time.Time{}
+	u.recent = nil
+}
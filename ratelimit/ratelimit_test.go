@@ -0,0 +1,145 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func newTestLimiter(cfg Config) (*Limiter, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	l := NewLimiter(cfg)
+	l.now = clock.now
+	return l, clock
+}
+
+func TestTokenBucket(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FloodMessages = 0
+	l, clock := newTestLimiter(cfg)
+
+	for i := 0; i < cfg.Burst; i++ {
+		if v := l.Allow(1); v != Allowed {
+			t.Fatalf("message %d: expected Allowed, got %v", i, v)
+		}
+	}
+	if v := l.Allow(1); v != Limited {
+		t.Errorf("expected Limited after burst, got %v", v)
+	}
+	if v := l.Allow(2); v != Allowed {
+		t.Errorf("other users should not be limited, got %v", v)
+	}
+
+	clock.t = clock.t.Add(time.Duration(float64(time.Second) / cfg.Rate))
+	if v := l.Allow(1); v != Allowed {
+		t.Errorf("expected Allowed after refill, got %v", v)
+	}
+}
+
+func TestFloodBan(t *testing.T) {
+	cfg := DefaultConfig()
+	l, clock := newTestLimiter(cfg)
+
+	var v Verdict
+	for i := 0; i < cfg.FloodMessages; i++ {
+		v = l.Allow(1)
+	}
+	if v != JustBanned {
+		t.Fatalf("expected JustBanned, got %v", v)
+	}
+	if v := l.Allow(1); v != Banned {
+		t.Errorf("expected Banned, got %v", v)
+	}
+
+	clock.t = clock.t.Add(cfg.BanDuration + time.Minute)
+	if v := l.Allow(1); v != Allowed {
+		t.Errorf("expected Allowed after ban, got %v", v)
+	}
+}
+
+func TestConcurrentLookups(t *testing.T) {
+	cfg := DefaultConfig()
+	l, _ := newTestLimiter(cfg)
+
+	for i := 0; i < cfg.MaxConcurrent; i++ {
+		if !l.Acquire(1) {
+			t.Fatalf("lookup %d should be allowed", i)
+		}
+	}
+	if l.Acquire(1) {
+		t.Errorf("expected lookup cap")
+	}
+	l.Release(1)
+	if !l.Acquire(1) {
+		t.Errorf("expected lookup after release")
+	}
+}
+
+func TestQueue(t *testing.T) {
+	q := NewQueue(1)
+	if err := q.Wait(context.Background(), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	positions := make(chan int, 1)
+	done := make(chan error)
+	go func() {
+		done <- q.Wait(context.Background(), func(position int) { positions <- position })
+	}()
+
+	if p := <-positions; p != 1 {
+		t.Errorf("expected position 1, got %d", p)
+	}
+	q.Done()
+	if err := <-done; err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := q.Wait(ctx, nil); err == nil {
+		t.Errorf("expected error for cancelled context")
+	}
+}
+
+func TestManualBan(t *testing.T) {
+	l, clock := newTestLimiter(DefaultConfig())
+
+	l.Ban(1, 0)
+	clock.t = clock.t.AddDate(10, 0, 0)
+	if v := l.Allow(1); v != Banned {
+		t.Errorf("expected Banned, got %v", v)
+	}
+
+	l.Unban(1)
+	if v := l.Allow(1); v != Allowed {
+		t.Errorf("expected Allowed after unban, got %v", v)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	l, clock := newTestLimiter(DefaultConfig())
+
+	l.Allow(1)
+	l.Allow(2)
+	l.Acquire(2)
+	l.Ban(3, time.Hour*24)
+
+	clock.t = clock.t.Add(pruneEvery)
+	l.Allow(4)
+	if len(l.users) != 3 {
+		t.Errorf("expected idle user 1 to be forgotten, got %d users", len(l.users))
+	}
+	if _, ok := l.users[1]; ok {
+		t.Errorf("idle user is kept")
+	}
+	if v := l.Allow(3); v != Banned {
+		t.Errorf("banned users must be kept, got %v", v)
+	}
+}
@@ -0,0 +1,34 @@
+package render
+
+import (
+	"fmt"
+	"mashinki/i18n"
+	"mashinki/taxes"
+	"strings"
+)
+
+// BudgetMarkdown renders the most expensive listing price that fits into the budget
+func BudgetMarkdown(b taxes.BudgetResult, lang i18n.Lang) string {
+	r := b.Result.Localize(lang)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "💰 *%s*\n", i18n.T(lang, "budget.title", b.Budget))
+	fmt.Fprintf(&sb, "🔧 %d %s, %s\n\n", r.Car.EngineSize, i18n.T(lang, "unit.cc"), r.Car.Year)
+	fmt.Fprintf(&sb, "🏷 %s: *¥%.0f* (%.2f ₽)\n", i18n.T(lang, "budget.max_price"), b.MaxPrice, r.Amount(taxes.KindCarPrice))
+	fmt.Fprintf(&sb, "📊 %s: %s\n", i18n.T(lang, "result.band"), r.TaxBand)
+
+	for _, item := range r.Items {
+		if item.Kind == taxes.KindCarPrice || item.Kind == taxes.KindExpense {
+			continue
+		}
+		fmt.Fprintf(&sb, "💳 %s: %.2f ₽\n", item.Name, item.Amount)
+	}
+	fmt.Fprintf(&sb, "🚚 %s: %.2f ₽\n💵 %s: %.2f ₽\n",
+		i18n.T(lang, "result.expenses"), r.Amount(taxes.KindExpense),
+		i18n.T(lang, "compare.total"), r.Total)
+
+	if b.AtBandLimit {
+		sb.WriteString("\n⚠️ " + i18n.T(lang, "budget.band_limit"))
+	}
+	return sb.String()
+}
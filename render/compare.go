@@ -0,0 +1,107 @@
+package render
+
+import (
+	"fmt"
+	"mashinki/i18n"
+	"mashinki/taxes"
+	"strings"
+)
+
+// CompareMarkdown renders several results side by side
+// and marks the one with the cheapest landed cost
+func CompareMarkdown(results []taxes.Result, lang i18n.Lang) string {
+	if len(results) == 0 {
+		return ""
+	}
+
+	cheapest := cheapestResult(results)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "⚖️ *%s*\n", i18n.T(lang, "compare.title"))
+
+	for i, r := range results {
+		r = r.Localize(lang)
+		mark := ""
+		if i == cheapest {
+			mark = " 🏆"
+		}
+
+		fmt.Fprintf(&sb,
+			"\n*%d. %s*%s\n"+
+				"📅 %s  📊 %v\n"+
+				"🔧 %d %s, %d %s, %s, %s\n"+
+				"💰 %s: %.2f %s\n"+
+				"📐 %s: %s\n"+
+				"💳 %s: %.2f ₽\n"+
+				"💳 %s: %.2f ₽\n"+
+				"💳 %s: %.2f ₽\n",
+			i+1, EscapeMarkdown(r.Car.FullName), mark,
+			r.Car.Year, mileage(lang, r.Car.Mileage),
+			r.Car.EngineSize, i18n.T(lang, "unit.cc"), r.Car.Power, i18n.T(lang, "unit.kw"), EscapeMarkdown(r.Car.Drive), EscapeMarkdown(r.Car.FuelType),
+			i18n.T(lang, "result.price"), r.Car.Price, i18n.T(lang, "result.cny"),
+			i18n.T(lang, "result.band"), r.TaxBand,
+			i18n.T(lang, "item.customs_duty"), r.Amount(taxes.KindCustomsDuty),
+			i18n.T(lang, "item.customs_fee"), r.Amount(taxes.KindCustomsFee),
+			i18n.T(lang, "compare.recycling"), r.Amount(taxes.KindRecyclingFee),
+		)
+		if fee := r.Amount(taxes.KindRegistrationFee); fee > 0 {
+			fmt.Fprintf(&sb, "💳 %s: %.2f ₽\n", i18n.T(lang, "item.registration_fee"), fee)
+		}
+		fmt.Fprintf(&sb, "🚚 %s: %.2f ₽\n💵 %s: %.2f ₽\n",
+			i18n.T(lang, "result.expenses"), r.Amount(taxes.KindExpense),
+			i18n.T(lang, "compare.total"), r.Total)
+	}
+
+	sb.WriteString("\n🏆 " + i18n.T(lang, "compare.cheapest",
+		cheapest+1, EscapeMarkdown(results[cheapest].Car.FullName), fmt.Sprintf("%.2f", results[cheapest].Total)))
+
+	return sb.String()
+}
+
+// CompareCountriesMarkdown renders the same car cleared in different countries
+func CompareCountriesMarkdown(results []taxes.Result, lang i18n.Lang) string {
+	if len(results) == 0 {
+		return ""
+	}
+
+	cheapest := cheapestResult(results)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "🌍 *%s*\n*%s*\n", i18n.T(lang, "countries.title"), EscapeMarkdown(results[0].Car.FullName))
+
+	for i, r := range results {
+		r = r.Localize(lang)
+		mark := ""
+		if i == cheapest {
+			mark = " 🏆"
+		}
+
+		fmt.Fprintf(&sb, "\n*%s*%s — %s\n", i18n.T(lang, "country."+r.Country), mark, r.ProfileTitle)
+		for _, item := range r.Items {
+			if item.Kind == taxes.KindCarPrice || item.Kind == taxes.KindExpense {
+				continue
+			}
+			fmt.Fprintf(&sb, "💳 %s: %.2f ₽\n", item.Name, item.Amount)
+		}
+		fmt.Fprintf(&sb, "🚚 %s: %.2f ₽\n💵 %s: %.2f ₽\n",
+			i18n.T(lang, "result.expenses"), r.Amount(taxes.KindExpense),
+			i18n.T(lang, "compare.total"), r.Total)
+	}
+
+	best := results[cheapest]
+	sb.WriteString("\n🏆 " + i18n.T(lang, "countries.cheapest",
+		i18n.T(lang, "country."+best.Country), fmt.Sprintf("%.2f", best.Total)))
+
+	return sb.String()
+}
+
+// cheapestResult returns the index of the result with the lowest landed cost
+func cheapestResult(results []taxes.Result) int {
+	cheapest := 0
+	for i, r := range results {
+		if r.Total < results[cheapest].Total {
+			cheapest = i
+		}
+	}
+	return cheapest
+}
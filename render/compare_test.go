@@ -0,0 +1,50 @@
+package render
+
+import (
+	"mashinki/i18n"
+	"mashinki/taxes"
+	"strings"
+	"testing"
+)
+
+func TestCompareCountries(t *testing.T) {
+	r := testResult()
+	results := taxes.CompareCountries(r.Car)
+	if len(results) != len(taxes.Jurisdictions()) {
+		t.Fatalf("expected result for every country, got %d", len(results))
+	}
+
+	// the duty is common for the customs union
+	for _, res := range results[1:] {
+		if res.Amount(taxes.KindCustomsDuty) != results[0].Amount(taxes.KindCustomsDuty) {
+			t.Errorf("duty in %q differs from %q", res.Country, results[0].Country)
+		}
+	}
+
+	md := CompareCountriesMarkdown(results, i18n.Russian)
+	for _, want := range []string{"Россия", "Казахстан", "Кыргызстан", "Беларусь", "Регистрация", "🏆"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("comparison misses %q:\n%s", want, md)
+		}
+	}
+
+	r.Car.FullName = "A_B*C"
+	if md := CompareCountriesMarkdown(taxes.CompareCountries(r.Car), i18n.English); !strings.Contains(md, "*A\\_B\\*C*\n") {
+		t.Errorf("car name is not escaped:\n%s", md)
+	}
+
+	if kz := taxes.Calculate(r.Car, "almaty"); kz.Country != "kz" || kz.Amount(taxes.KindRegistrationFee) == 0 {
+		t.Errorf("expected kazakh calculation with registration, got %q", kz.Country)
+	}
+}
+
+func TestCompareEscapesNames(t *testing.T) {
+	r := testResult()
+	r.Car.FullName = "A_B*C"
+	r.Car.Drive = "4WD_[x]"
+
+	md := CompareMarkdown([]taxes.Result{r, r}, i18n.English)
+	if strings.Contains(md, "A_B*C") || strings.Count(md, `A\_B\*C`) != 3 || !strings.Contains(md, `4WD\_\[x]`) {
+		t.Errorf("car values are not escaped:\n%s", md)
+	}
+}
@@ -0,0 +1,40 @@
+package render
+
+import (
+	"mashinki/i18n"
+	"mashinki/parser"
+	"strings"
+)
+
+// IssuesMarkdown lists missing and suspicious fields of the car and fields not taken from che168.
+// Empty if the data is complete.
+func IssuesMarkdown(car parser.CarInfo, lang i18n.Lang) string {
+	var sb strings.Builder
+	if issues := car.Validate(); len(issues) > 0 {
+		sb.WriteString("⚠️ *" + i18n.T(lang, "issue.title") + "*\n")
+		for _, issue := range issues {
+			sb.WriteString("   • " + i18n.T(lang, "field."+string(issue.Field)) + ": " + i18n.T(lang, "issue."+string(issue.Kind)) + "\n")
+		}
+	}
+
+	// cars without sources were entered by hand completely
+	if car.Sources != nil {
+		for _, source := range []parser.Source{parser.SourceUser, parser.SourceDefault} {
+			if names := fieldsFrom(car, source, lang); len(names) > 0 {
+				sb.WriteString("✏️ " + i18n.T(lang, "issue."+string(source), strings.Join(names, ", ")) + "\n")
+			}
+		}
+	}
+	return sb.String()
+}
+
+// fieldsFrom names fields of the car taken from the source
+func fieldsFrom(car parser.CarInfo, source parser.Source, lang i18n.Lang) []string {
+	var names []string
+	for _, f := range parser.Fields {
+		if s, ok := car.Source(f); ok && s == source {
+			names = append(names, i18n.T(lang, "field."+string(f)))
+		}
+	}
+	return names
+}
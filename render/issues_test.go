@@ -0,0 +1,28 @@
+package render
+
+import (
+	"mashinki/i18n"
+	"mashinki/parser"
+	"strings"
+	"testing"
+)
+
+func TestIssuesMarkdown(t *testing.T) {
+	ci := parser.CarInfo{Price: 150_000, Year: "2021-05", EngineSize: 1998, Power: 140, FullName: "Audi",
+		Mileage: parser.ParseMileage("3万公里"), FuelType: "Бензин", Drive: "Передний привод"}
+	if md := IssuesMarkdown(ci, i18n.Russian); md != "" {
+		t.Errorf("complete car entered by hand has no issues, got %q", md)
+	}
+
+	ci.SetSource(parser.FieldFuelType, parser.SourceDefault)
+	ci.Power = 5_000
+	ci.Drive = ""
+	delete(ci.Sources, parser.FieldDrive)
+	md := IssuesMarkdown(ci, i18n.Russian)
+	for _, want := range []string{"⚠️ *Проверьте данные*", "• мощность: подозрительное значение", "• привод: не найдено",
+		"✏️ Значение по умолчанию: топливо"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("issues miss %q:\n%s", want, md)
+		}
+	}
+}
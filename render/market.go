@@ -0,0 +1,35 @@
+package render
+
+import (
+	"fmt"
+	"mashinki/i18n"
+	"mashinki/market"
+	"strings"
+)
+
+var verdictIcons = map[market.Verdict]string{
+	market.VerdictCheap:     "🟢",
+	market.VerdictFair:      "🟡",
+	market.VerdictExpensive: "🔴",
+}
+
+// MarketMarkdown renders the price of the car compared to listings of the same spec
+func MarketMarkdown(a market.Analysis, lang i18n.Lang) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "📈 *%s*\n", i18n.T(lang, "market.title"))
+	fmt.Fprintf(&sb, "%s *%s* (%+.0f%%)\n\n", verdictIcons[a.Verdict], i18n.T(lang, "market."+string(a.Verdict)), a.Diff*100)
+
+	if a.YearFrom > 0 {
+		sb.WriteString(i18n.T(lang, "market.listings_years", a.Stats.Count, a.YearFrom, a.YearTo) + "\n")
+	} else {
+		sb.WriteString(i18n.T(lang, "market.listings", a.Stats.Count) + "\n")
+	}
+	fmt.Fprintf(&sb, "💰 %s: ¥%.0f\n", i18n.T(lang, "result.price"), a.Price)
+	fmt.Fprintf(&sb, "📊 %s: ¥%.0f\n", i18n.T(lang, "market.median"), a.Stats.Median)
+	fmt.Fprintf(&sb, "↔️ %s: ¥%.0f – ¥%.0f\n", i18n.T(lang, "market.middle"), a.Stats.P25, a.Stats.P75)
+	fmt.Fprintf(&sb, "📉 %s\n", i18n.T(lang, "market.percentile", a.Percentile))
+	if a.PricePerKm > 0 && a.Stats.MedianPerKm > 0 {
+		fmt.Fprintf(&sb, "🛣 %s\n", i18n.T(lang, "market.per_km", a.PricePerKm, a.Stats.MedianPerKm))
+	}
+	return sb.String()
+}
@@ -0,0 +1,27 @@
+package render
+
+import (
+	"mashinki/i18n"
+	"mashinki/market"
+	"strings"
+	"testing"
+)
+
+func TestMarketMarkdown(t *testing.T) {
+	a := market.Analysis{YearFrom: 2020, YearTo: 2022, Price: 100_000, Percentile: 10, Diff: -0.1667,
+		PricePerKm: 5, Verdict: market.VerdictCheap,
+		Stats: market.Stats{Count: 5, Median: 120_000, P25: 110_000, P75: 130_000, MedianPerKm: 6}}
+
+	md := MarketMarkdown(a, i18n.Russian)
+	for _, want := range []string{"🟢 *Дешевле рынка* (-17%)", "5 объявлениями этой комплектации 2020–2022", "Медиана: ¥120000",
+		"¥110000 – ¥130000", "Дешевле этой машины 10% объявлений", "¥5.00 (медиана ¥6.00)"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("market summary misses %q:\n%s", want, md)
+		}
+	}
+
+	a.PricePerKm, a.YearFrom = 0, 0
+	if md = MarketMarkdown(a, i18n.English); strings.Contains(md, "per km") || !strings.Contains(md, "Compared with 5 listings of the spec\n") {
+		t.Errorf("unexpected summary without mileage and year:\n%s", md)
+	}
+}
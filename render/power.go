@@ -0,0 +1,22 @@
+package render
+
+import (
+	"fmt"
+	"mashinki/i18n"
+	"mashinki/parser"
+)
+
+// powerLine shows power in kilowatts and horsepower,
+// hybrids also get power of the engine and electric motors
+func powerLine(lang i18n.Lang, car parser.CarInfo) string {
+	unit := i18n.T(lang, "unit.kw")
+	if car.Power == 0 {
+		return fmt.Sprintf("0 %s", unit)
+	}
+
+	line := fmt.Sprintf("%d %s (%.0f %s)", car.Power, unit, parser.KwToHp(car.Power), i18n.T(lang, "unit.hp"))
+	if p := car.Powertrain; p.Hybrid() {
+		line += ", " + i18n.T(lang, "result.power_split", p.EngineKw, unit, p.Motors(), unit)
+	}
+	return line
+}
@@ -0,0 +1,17 @@
+package render
+
+import (
+	"mashinki/i18n"
+	"mashinki/parser"
+	"testing"
+)
+
+func TestPowerLine(t *testing.T) {
+	ci := parser.CarInfo{Power: 235, Powertrain: parser.Powertrain{EngineKw: 110, FrontMotorKw: 145, CombinedKw: 235}}
+	if line := powerLine(i18n.Russian, ci); line != "235 kW (320 л.с.), ДВС 110 kW + электромоторы 145 kW" {
+		t.Errorf("unexpected hybrid power %q", line)
+	}
+	if line := powerLine(i18n.English, parser.CarInfo{Power: 110}); line != "110 kW (150 hp)" {
+		t.Errorf("unexpected power %q", line)
+	}
+}
@@ -0,0 +1,173 @@
+// Package render turns calculation results into messages for different frontends
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	htmltemplate "html/template"
+	"mashinki/i18n"
+	"mashinki/parser"
+	"mashinki/taxes"
+	"strings"
+	"text/template"
+)
+
+// Layout of the result shared by Markdown and plain text renderers
+const resultTemplate = `🚗 {{bold (esc .Car.FullName)}}
+
+📅 {{t .Lang "result.year"}}: {{esc .Car.Year}}
+📊 {{t .Lang "result.mileage"}}: {{mileage .Lang .Car.Mileage}}
+💰 {{t .Lang "result.price"}}: {{printf "%.2f" .Car.Price}} {{t .Lang "result.cny"}}
+{{- with seller .Lang .Car.Seller}}
+🏪 {{t $.Lang "result.seller"}}: {{esc .}}
+{{- end}}
+
+🔧 {{t .Lang "result.specs"}}:
+   • {{t .Lang "result.engine"}}: {{.Car.EngineSize}} {{t .Lang "unit.cc"}}
+   • {{t .Lang "result.power"}}: {{power .Lang .Car}}
+   • {{t .Lang "result.drive"}}: {{esc .Car.Drive}}
+   • {{t .Lang "result.fuel"}}: {{esc .Car.FuelType}}
+
+📐 {{t .Lang "result.band"}}: {{.TaxBand}}
+🌍 {{t .Lang "result.country"}}: {{t .Lang (printf "country.%s" .Country)}}
+
+💳 {{t .Lang "result.customs"}}:
+{{- range customs .Result}}
+   • {{.Name}}: {{printf "%.2f" .Amount}} ₽{{if .Note}} ({{.Note}}){{end}}
+{{- end}}
+
+🚚 {{t .Lang "result.expenses"}} ({{.ProfileTitle}}):
+{{- range .ItemsOf "expense"}}
+   • {{.Name}}: {{printf "%.2f" .Amount}} ₽
+{{- end}}
+
+💱 {{t .Lang "result.rates"}}: ¥1 = {{.Rates.CNY}} ₽, €1 = {{.Rates.EUR}} ₽
+
+💵 {{t .Lang "result.total"}}: {{printf "%.2f" .Total}} ₽`
+
+const htmlTemplate = `<div class="car-result">
+<h2>{{.Car.FullName}}</h2>
+<ul>
+<li>{{t .Lang "result.year"}}: {{.Car.Year}}</li>
+<li>{{t .Lang "result.mileage"}}: {{mileage .Lang .Car.Mileage}}</li>
+<li>{{t .Lang "result.price"}}: {{printf "%.2f" .Car.Price}} ¥</li>
+{{- with seller .Lang .Car.Seller}}
+<li>{{t $.Lang "result.seller"}}: {{.}}</li>
+{{- end}}
+<li>{{t .Lang "result.engine"}}: {{.Car.EngineSize}} {{t .Lang "unit.cc"}}</li>
+<li>{{t .Lang "result.power"}}: {{power .Lang .Car}}</li>
+<li>{{t .Lang "result.drive"}}: {{.Car.Drive}}</li>
+<li>{{t .Lang "result.fuel"}}: {{.Car.FuelType}}</li>
+<li>{{t .Lang "result.band"}}: {{.TaxBand}}</li>
+<li>{{t .Lang "result.country"}}: {{t .Lang (printf "country.%s" .Country)}}</li>
+</ul>
+<table>
+<tr><th>{{t .Lang "result.item"}}</th><th>{{t .Lang "result.amount"}}</th><th>{{t .Lang "result.note"}}</th></tr>
+{{- range .Items}}
+<tr><td>{{.Name}}</td><td>{{printf "%.2f" .Amount}}</td><td>{{.Note}}</td></tr>
+{{- end}}
+<tr><th>{{t .Lang "result.total"}}</th><th>{{printf "%.2f" .Total}}</th><th></th></tr>
+</table>
+<p>{{t .Lang "result.rates"}}: ¥1 = {{.Rates.CNY}} ₽, €1 = {{.Rates.EUR}} ₽. {{t .Lang "result.route"}}: {{.ProfileTitle}}.</p>
+</div>`
+
+// data is the result localized for the template
+type data struct {
+	taxes.Result
+	Lang i18n.Lang
+}
+
+func localize(r taxes.Result, lang i18n.Lang) data {
+	return data{Result: r.Localize(lang), Lang: lang}
+}
+
+var (
+	markdownTmpl = template.Must(template.New("markdown").Funcs(template.FuncMap{
+		"bold":    func(s string) string { return "*" + s + "*" },
+		"esc":     EscapeMarkdown,
+		"customs": customsItems,
+		"mileage": mileage,
+		"power":   powerLine,
+		"seller":  sellerLine,
+		"t":       t,
+	}).Parse(resultTemplate))
+
+	textTmpl = template.Must(template.New("text").Funcs(template.FuncMap{
+		"bold":    func(s string) string { return s },
+		"esc":     func(s string) string { return s },
+		"customs": customsItems,
+		"mileage": mileage,
+		"power":   powerLine,
+		"seller":  sellerLine,
+		"t":       t,
+	}).Parse(resultTemplate))
+
+	htmlTmpl = htmltemplate.Must(htmltemplate.New("html").Funcs(htmltemplate.FuncMap{
+		"mileage": mileage,
+		"power":   powerLine,
+		"seller":  sellerLine,
+		"t":       t,
+	}).Parse(htmlTemplate))
+)
+
+// markdownEscaper escapes characters of Telegram Markdown
+var markdownEscaper = strings.NewReplacer("_", "\\_", "*", "\\*", "`", "\\`", "[", "\\[")
+
+// EscapeMarkdown escapes scraped and user-entered text put into Markdown messages
+func EscapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// t translates template labels
+func t(lang i18n.Lang, key string) string {
+	return i18n.T(lang, key)
+}
+
+// mileage shows the mileage in kilometers of the language
+func mileage(lang i18n.Lang, m parser.Mileage) string {
+	return m.Format(i18n.T(lang, "unit.km"))
+}
+
+// customsItems returns all payments of the destination country
+func customsItems(r taxes.Result) []taxes.LineItem {
+	var items []taxes.LineItem
+	for _, item := range r.Items {
+		switch item.Kind {
+		case taxes.KindCustomsDuty, taxes.KindCustomsFee, taxes.KindRecyclingFee, taxes.KindRegistrationFee:
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Markdown renders the result for Telegram messages with Markdown parse mode
+func Markdown(r taxes.Result, lang i18n.Lang) string {
+	return execute(markdownTmpl, localize(r, lang))
+}
+
+// Text renders the result as plain text
+func Text(r taxes.Result, lang i18n.Lang) string {
+	return execute(textTmpl, localize(r, lang))
+}
+
+// HTML renders the result as an HTML fragment
+func HTML(r taxes.Result, lang i18n.Lang) string {
+	var buf bytes.Buffer
+	if err := htmlTmpl.Execute(&buf, localize(r, lang)); err != nil {
+		return err.Error()
+	}
+	return buf.String()
+}
+
+// JSON renders the result as indented JSON
+func JSON(r taxes.Result) ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+func execute(tmpl *template.Template, data interface{}) string {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return err.Error()
+	}
+	return buf.String()
+}
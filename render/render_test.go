@@ -0,0 +1,75 @@
+package render
+
+import (
+	"encoding/json"
+	"mashinki/i18n"
+	"mashinki/parser"
+	"mashinki/taxes"
+	"strings"
+	"testing"
+)
+
+func testResult() taxes.Result {
+	return taxes.Calculate(parser.CarInfo{
+		FullName:   "<Test> car",
+		Year:       "2021-05",
+		Price:      150_000,
+		EngineSize: 1998,
+	}, "")
+}
+
+func TestRenderers(t *testing.T) {
+	r := testResult()
+
+	md := Markdown(r, i18n.Russian)
+	if !strings.HasPrefix(md, "🚗 *<Test> car*") {
+		t.Errorf("unexpected markdown header:\n%s", md)
+	}
+	if !strings.Contains(md, "Пошлина") || !strings.Contains(md, "Итого к оплате") {
+		t.Errorf("markdown misses payments:\n%s", md)
+	}
+
+	if text := Text(r, i18n.Russian); strings.Contains(text, "*") {
+		t.Errorf("plain text contains markdown:\n%s", text)
+	}
+
+	if html := HTML(r, i18n.Russian); !strings.Contains(html, "&lt;Test&gt; car") {
+		t.Errorf("html is not escaped:\n%s", html)
+	}
+
+	data, err := JSON(r)
+	if err != nil {
+		t.Fatalf("error while rendering JSON: %v", err)
+	}
+	var decoded taxes.Result
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if decoded.Total != r.Total {
+		t.Errorf("expected total %v, got %v", r.Total, decoded.Total)
+	}
+}
+
+func TestLocalizedRenderers(t *testing.T) {
+	r := testResult()
+
+	md := Markdown(r, i18n.English)
+	for _, want := range []string{"Customs duty", "Total", "3–5 years, engine 1 800 – 2 300 cm³"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("english markdown misses %q:\n%s", want, md)
+		}
+	}
+	if strings.Contains(md, "Пошлина") {
+		t.Errorf("english markdown contains russian labels:\n%s", md)
+	}
+
+	// the result itself stays in the default language
+	if r.TaxBand != "3–5 лет, объём 1 800 – 2 300 см³" {
+		t.Errorf("unexpected tax band: %q", r.TaxBand)
+	}
+
+	cmp := CompareMarkdown([]taxes.Result{r, r}, i18n.Kazakh)
+	if !strings.Contains(cmp, "Көліктерді салыстыру") || !strings.Contains(cmp, "Кедендік баж") {
+		t.Errorf("unexpected kazakh comparison:\n%s", cmp)
+	}
+}
@@ -0,0 +1,35 @@
+package render
+
+import (
+	"mashinki/i18n"
+	"mashinki/parser"
+	"strings"
+)
+
+// RiskMarkdown renders the condensed inspection report, serious risks go first
+func RiskMarkdown(s parser.RiskSummary, lang i18n.Lang) string {
+	var sb strings.Builder
+	sb.WriteString("🛡 *" + i18n.T(lang, "risk.title") + "*\n")
+
+	if s.Warning {
+		names := make([]string, len(s.Failed))
+		for i, category := range s.Failed {
+			names[i] = i18n.T(lang, "risk."+string(category))
+		}
+		sb.WriteString("⚠️ *" + i18n.T(lang, "risk.serious", strings.Join(names, ", ")) + "*\n")
+	} else {
+		sb.WriteString("✅ " + i18n.T(lang, "risk.clean") + "\n")
+	}
+
+	sb.WriteString("📋 " + i18n.T(lang, "risk.checks", s.Passed, s.Checks) + "\n")
+	if s.Defects > 0 {
+		sb.WriteString("🔧 " + i18n.T(lang, "risk.defects", s.Defects) + "\n")
+	}
+	if s.Repainted > 0 {
+		sb.WriteString("🎨 " + i18n.T(lang, "risk.repainted", s.Repainted) + "\n")
+	}
+	if s.Transfers >= 0 {
+		sb.WriteString("🔁 " + i18n.T(lang, "risk.transfers", s.Transfers) + "\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,25 @@
+package render
+
+import (
+	"mashinki/i18n"
+	"mashinki/parser"
+	"strings"
+	"testing"
+)
+
+func TestRiskMarkdown(t *testing.T) {
+	s := parser.RiskSummary{Failed: []parser.RiskCategory{parser.RiskAccident, parser.RiskFlood}, Checks: 10, Passed: 8,
+		Defects: 2, Transfers: -1, Warning: true}
+
+	md := RiskMarkdown(s, i18n.Russian)
+	if !strings.Contains(md, "⚠️ *Внимание: следы ДТП, затопление*") || !strings.Contains(md, "Пройдено проверок: 8 из 10") {
+		t.Errorf("unexpected risk summary:\n%s", md)
+	}
+	if strings.Contains(md, "Смен владельца") || strings.Contains(md, "Перекрашенных") {
+		t.Errorf("unknown transfers and zero repaints should be skipped:\n%s", md)
+	}
+
+	if md = RiskMarkdown(parser.RiskSummary{Checks: 3, Passed: 3}, i18n.English); !strings.Contains(md, "✅ No signs") {
+		t.Errorf("unexpected clean summary:\n%s", md)
+	}
+}
@@ -0,0 +1,43 @@
+package render
+
+import (
+	"mashinki/i18n"
+	"mashinki/parser"
+	"strings"
+)
+
+// sellerLine describes the seller, its location and che168 badges in one line.
+// Empty if the seller is unknown.
+func sellerLine(lang i18n.Lang, s parser.Seller) string {
+	if s.Type == "" {
+		return ""
+	}
+
+	line := i18n.T(lang, "seller."+string(s.Type))
+	if s.Name != "" {
+		line += " " + s.Name
+	}
+
+	var place []string
+	if s.City != "" {
+		place = append(place, s.City)
+	}
+	if s.Province != "" && s.Province != s.City {
+		place = append(place, s.Province)
+	}
+	if len(place) > 0 {
+		line += " (" + strings.Join(place, ", ") + ")"
+	}
+	if s.Published != "" {
+		line += ", " + i18n.T(lang, "seller.published", s.Published)
+	}
+
+	if len(s.Badges) > 0 {
+		names := make([]string, len(s.Badges))
+		for i, badge := range s.Badges {
+			names[i] = i18n.T(lang, "badge."+badge)
+		}
+		line += ", " + strings.Join(names, ", ")
+	}
+	return line
+}
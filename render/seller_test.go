@@ -0,0 +1,27 @@
+package render
+
+import (
+	"mashinki/i18n"
+	"mashinki/parser"
+	"mashinki/taxes"
+	"strings"
+	"testing"
+)
+
+func TestSellerLine(t *testing.T) {
+	if md := Markdown(testResult(), i18n.Russian); strings.Contains(md, "Продавец") {
+		t.Errorf("unknown seller should be skipped:\n%s", md)
+	}
+
+	ci := parser.CarInfo{Year: "2021-05", Price: 150_000, EngineSize: 1998, Seller: parser.Seller{
+		Type: parser.SellerDealer, DealerID: "1", Name: "车行", City: "苏州", Province: "江苏",
+		Published: "2025-05-01", Badges: []string{"certified"},
+	}}
+	md := Markdown(taxes.Calculate(ci, ""), i18n.Russian)
+	if !strings.Contains(md, "🏪 Продавец: дилер 车行 (苏州, 江苏), опубликовано 2025-05-01, сертифицирована che168\n") {
+		t.Errorf("unexpected seller line:\n%s", md)
+	}
+	if html := HTML(taxes.Calculate(ci, ""), i18n.English); !strings.Contains(html, "<li>Seller: dealer 车行 (苏州, 江苏)") {
+		t.Errorf("unexpected html seller:\n%s", html)
+	}
+}
@@ -0,0 +1,116 @@
+// Package reputation remembers how reliable che168 dealers are
+// by their removed listings and listings not matching the description
+package reputation
+
+import (
+	"fmt"
+	"mashinki/storage"
+	"sync"
+	"time"
+)
+
+const (
+	minChecks     = 3   // меньше машин дилера не хватает для оценки
+	unreliableBad = 0.3 // доля плохих объявлений, с которой дилер ненадежен
+)
+
+// Outcome is what was found out about a listing of the dealer
+type Outcome string
+
+const (
+	OutcomeOK       Outcome = "ok"
+	OutcomeStale    Outcome = "stale"    // объявление снято или продано
+	OutcomeMismatch Outcome = "mismatch" // цена или описание не совпадают
+)
+
+// Dealer is the record of one dealer
+type Dealer struct {
+	ID       string             `json:"id"`
+	Name     string             `json:"name,omitempty"`
+	Cars     map[string]Outcome `json:"cars"` // итог по каждой проверенной машине
+	LastSeen time.Time          `json:"last_seen"`
+}
+
+// Stats counts checked cars of the dealer by outcome
+func (d Dealer) Stats() (checked, stale, mismatched int) {
+	for _, outcome := range d.Cars {
+		checked++
+		switch outcome {
+		case OutcomeStale:
+			stale++
+		case OutcomeMismatch:
+			mismatched++
+		}
+	}
+	return checked, stale, mismatched
+}
+
+// Unreliable tells whether too many listings of the dealer were stale or mismatched
+func (d Dealer) Unreliable() bool {
+	checked, stale, mismatched := d.Stats()
+	if checked < minChecks {
+		return false
+	}
+	return float64(stale+mismatched)/float64(checked) >= unreliableBad
+}
+
+// Store keeps dealers in a JSON file
+type Store struct {
+	file    *storage.File
+	mu      sync.Mutex
+	dealers map[string]*Dealer
+}
+
+// NewStore loads dealers from the file
+func NewStore(file *storage.File) (*Store, error) {
+	s := &Store{file: file, dealers: make(map[string]*Dealer)}
+	if err := file.Load(&s.dealers); err != nil {
+		return nil, fmt.Errorf("error while loading dealers: %v", err)
+	}
+	return s, nil
+}
+
+// Record saves the outcome of the dealer's car. A bad outcome is never replaced by ok,
+// the listing that was removed once or reported by a user stays bad.
+// Empty dealerID means a private seller and is ignored.
+func (s *Store) Record(dealerID, name, carID string, outcome Outcome) error {
+	if dealerID == "" || carID == "" {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d, ok := s.dealers[dealerID]
+	if !ok {
+		d = &Dealer{ID: dealerID, Cars: make(map[string]Outcome)}
+		s.dealers[dealerID] = d
+	}
+	if name != "" {
+		d.Name = name
+	}
+	d.LastSeen = time.Now()
+
+	if _, seen := d.Cars[carID]; !seen || outcome != OutcomeOK {
+		d.Cars[carID] = outcome
+	}
+	return s.file.Save(s.dealers)
+}
+
+// Get returns the dealer record, false if the dealer was never checked
+func (s *Store) Get(dealerID string) (Dealer, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d, ok := s.dealers[dealerID]
+	if !ok {
+		return Dealer{}, false
+	}
+
+	dealer := *d
+	dealer.Cars = make(map[string]Outcome, len(d.Cars))
+	for carID, outcome := range d.Cars {
+		dealer.Cars[carID] = outcome
+	}
+	return dealer, true
+}
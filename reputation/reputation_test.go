@@ -0,0 +1,65 @@
+package reputation
+
+import (
+	"mashinki/storage"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	file := storage.Open(t.TempDir(), "dealers.json")
+	store, err := NewStore(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// private sellers are not kept
+	if err := store.Record("", "", "1", OutcomeStale); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := store.Get(""); ok {
+		t.Errorf("private seller must not be recorded")
+	}
+
+	for _, r := range []struct {
+		carID   string
+		outcome Outcome
+	}{
+		{"1", OutcomeOK},
+		{"2", OutcomeOK},
+		{"2", OutcomeMismatch},
+		{"2", OutcomeOK}, // a report is not overwritten by a later check
+	} {
+		if err := store.Record("10", "车行", r.carID, r.outcome); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	d, ok := store.Get("10")
+	if checked, stale, mismatched := d.Stats(); !ok || checked != 2 || stale != 0 || mismatched != 1 {
+		t.Fatalf("unexpected stats %d/%d/%d", checked, stale, mismatched)
+	}
+	if d.Unreliable() {
+		t.Errorf("two cars are not enough to judge the dealer")
+	}
+
+	if err := store.Record("10", "", "3", OutcomeStale); err != nil {
+		t.Fatal(err)
+	}
+
+	// everything is kept after restart
+	store, err = NewStore(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, _ = store.Get("10")
+	if !d.Unreliable() || d.Name != "车行" {
+		t.Errorf("expected unreliable dealer, got %+v", d)
+	}
+
+	for _, carID := range []string{"4", "5", "6", "7"} {
+		store.Record("10", "", carID, OutcomeOK)
+	}
+	if d, _ = store.Get("10"); d.Unreliable() {
+		t.Errorf("2 bad of 7 cars is below the threshold")
+	}
+}
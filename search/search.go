@@ -0,0 +1,325 @@
+// Package search finds che168 listings by criteria and ranks them by landed cost
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mashinki/batch"
+	"mashinki/parser"
+	"mashinki/taxes"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultLimit  = 10 // сколько машин возвращается по умолчанию
+	maxPages      = 5  // сколько страниц поиска просматривается
+	maxCandidates = 30 // у скольких машин запрашиваются характеристики
+	detailWorkers = 5
+)
+
+// ErrNoCriteria means that the search text has no known criteria
+var ErrNoCriteria = errors.New("no search criteria")
+
+// Criteria filters che168 listings
+type Criteria struct {
+	Brand string `json:"brand"` // латиница из адреса che168: aodi
+	Model string `json:"model"` // aodia4l
+	City  string `json:"city"`  // beijing, пусто - вся страна
+
+	YearFrom   int     `json:"year_from"`
+	YearTo     int     `json:"year_to"`
+	PriceFrom  float64 `json:"price_from"` // в юанях
+	PriceTo    float64 `json:"price_to"`
+	MaxMileage float64 `json:"max_mileage"` // в километрах
+
+	// checked after calculation
+	Fuel     string  `json:"fuel"`      // часть названия топлива на языке пользователя
+	MaxTotal float64 `json:"max_total"` // максимальная стоимость под ключ в рублях
+}
+
+// Car is a found listing with the calculated landed cost
+type Car struct {
+	Listing parser.Listing `json:"listing"`
+	Result  taxes.Result   `json:"result"`
+}
+
+// Searcher queries che168 search pages and listing details
+type Searcher struct {
+	Listings func(q parser.SearchQuery, page int) ([]parser.Listing, error)
+	Lookup   func(url string) (parser.CarInfo, error)
+}
+
+// New creates a searcher requesting che168 with texts in the language
+func New(lang string) Searcher {
+	return Searcher{
+		Listings: parser.SearchListings,
+		Lookup: func(url string) (parser.CarInfo, error) {
+			return parser.GetCarInfoIn(url, lang)
+		},
+	}
+}
+
+// ParseCriteria parses "brand=aodi model=aodia4l year=2019-2022 price=100000-200000 mileage=80000 city=beijing".
+// fuel=бензин and total=3000000 filter cars by fuel type and landed cost.
+func ParseCriteria(text string) (Criteria, error) {
+	var c Criteria
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return c, ErrNoCriteria
+	}
+
+	for _, field := range fields {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok || value == "" {
+			return c, fmt.Errorf("invalid criterion %q", field)
+		}
+
+		var err error
+		switch strings.ToLower(key) {
+		case "brand":
+			c.Brand = strings.ToLower(value)
+		case "model":
+			c.Model = strings.ToLower(value)
+		case "city":
+			c.City = strings.ToLower(value)
+		case "year":
+			var from, to float64
+			from, to, err = parseRange(value)
+			c.YearFrom, c.YearTo = int(from), int(to)
+		case "price":
+			c.PriceFrom, c.PriceTo, err = parseRange(value)
+		case "mileage":
+			c.MaxMileage, err = strconv.ParseFloat(value, 64)
+		case "fuel":
+			c.Fuel = strings.ToLower(value)
+		case "total":
+			c.MaxTotal, err = strconv.ParseFloat(value, 64)
+		default:
+			return c, fmt.Errorf("unknown criterion %q", key)
+		}
+		if err != nil {
+			return c, fmt.Errorf("invalid %s %q: %v", key, value, err)
+		}
+	}
+	return c, nil
+}
+
+// parseRange parses "2019-2022", "2019-", "-2022" or a single value
+func parseRange(value string) (float64, float64, error) {
+	fromStr, toStr, isRange := strings.Cut(value, "-")
+	if !isRange {
+		toStr = fromStr
+	}
+
+	var from, to float64
+	var err error
+	if fromStr != "" {
+		if from, err = strconv.ParseFloat(fromStr, 64); err != nil {
+			return 0, 0, err
+		}
+	}
+	if toStr != "" {
+		if to, err = strconv.ParseFloat(toStr, 64); err != nil {
+			return 0, 0, err
+		}
+	}
+	if from < 0 || to < 0 || (to > 0 && from > to) {
+		return 0, 0, errors.New("invalid range")
+	}
+	return from, to, nil
+}
+
+// String formats criteria the way ParseCriteria reads them
+func (c Criteria) String() string {
+	var parts []string
+	add := func(key, value string) {
+		if value != "" {
+			parts = append(parts, key+"="+value)
+		}
+	}
+
+	add("brand", c.Brand)
+	add("model", c.Model)
+	add("city", c.City)
+	add("year", formatRange(float64(c.YearFrom), float64(c.YearTo)))
+	add("price", formatRange(c.PriceFrom, c.PriceTo))
+	add("mileage", formatRange(c.MaxMileage, c.MaxMileage))
+	add("fuel", c.Fuel)
+	add("total", formatRange(c.MaxTotal, c.MaxTotal))
+	return strings.Join(parts, " ")
+}
+
+func formatRange(from, to float64) string {
+	format := func(v float64) string {
+		if v == 0 {
+			return ""
+		}
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+
+	if from == to {
+		return format(from)
+	}
+	return format(from) + "-" + format(to)
+}
+
+// Matches tells whether the listing card satisfies the criteria
+func (c Criteria) Matches(l parser.Listing) bool {
+	switch {
+	case c.YearFrom > 0 && l.Year < c.YearFrom:
+		return false
+	case c.YearTo > 0 && l.Year > c.YearTo:
+		return false
+	case c.PriceFrom > 0 && l.Price < c.PriceFrom:
+		return false
+	case c.PriceTo > 0 && l.Price > c.PriceTo:
+		return false
+	case c.MaxMileage > 0 && l.Mileage > c.MaxMileage:
+		return false
+	}
+	return true
+}
+
+// MatchesResult tells whether the calculated car satisfies the criteria
+func (c Criteria) MatchesResult(r taxes.Result) bool {
+	if c.Fuel != "" && !strings.Contains(strings.ToLower(r.Car.FuelType), c.Fuel) {
+		return false
+	}
+	return c.MaxTotal <= 0 || r.Total <= c.MaxTotal
+}
+
+// Search goes through che168 search pages, calculates matching listings
+// and returns up to limit cars with the lowest landed cost
+func (s Searcher) Search(ctx context.Context, c Criteria, limit int, profile string) ([]Car, error) {
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+
+	found, err := s.Find(ctx, c, profile, nil)
+	cars := found.Cars
+	if len(cars) > limit {
+		cars = cars[:limit]
+	}
+	return cars, err
+}
+
+// Found are cars found by Find
+type Found struct {
+	Cars     []Car    // подходящие машины, самые дешевые под ключ первыми
+	Rejected []string // id рассчитанных машин, которые не подошли по топливу или стоимости под ключ
+}
+
+// query puts the criteria into the che168 search, so pages have only matching cars.
+// Cards are still checked by Matches: che168 knows only the age of cars, not the year.
+func (c Criteria) query(now time.Time) parser.SearchQuery {
+	q := parser.SearchQuery{City: c.City, Brand: c.Brand, Model: c.Model,
+		PriceFrom: c.PriceFrom, PriceTo: c.PriceTo, MaxMileage: c.MaxMileage}
+	if c.YearTo > 0 {
+		q.AgeFrom = max(0, now.Year()-c.YearTo)
+	}
+	if c.YearFrom > 0 {
+		q.AgeTo = max(1, now.Year()-c.YearFrom)
+	}
+	return q
+}
+
+// ListingIDs returns IDs of all listings on che168 search pages of the criteria
+// without requesting the listings
+func (s Searcher) ListingIDs(ctx context.Context, c Criteria) ([]string, error) {
+	query := c.query(time.Now())
+
+	var ids []string
+	for page := 1; page <= maxPages; page++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		listings, err := s.Listings(query, page)
+		if err != nil {
+			return nil, fmt.Errorf("error while getting search results: %v", err)
+		}
+		if len(listings) == 0 {
+			break
+		}
+		for _, l := range listings {
+			ids = append(ids, l.CarId)
+		}
+	}
+	return ids, nil
+}
+
+// Find returns all matching cars from che168 search pages sorted by landed cost.
+// Listings with car IDs for which skip returns true are not requested.
+func (s Searcher) Find(ctx context.Context, c Criteria, profile string, skip func(carID string) bool) (Found, error) {
+	query := c.query(time.Now())
+
+	var candidates []parser.Listing
+	seen := make(map[string]bool)
+	for page := 1; page <= maxPages && len(candidates) < maxCandidates; page++ {
+		if err := ctx.Err(); err != nil {
+			return Found{}, err
+		}
+
+		listings, err := s.Listings(query, page)
+		if err != nil {
+			// later pages may fail, cars from earlier ones are still useful
+			if page == 1 {
+				return Found{}, fmt.Errorf("error while getting search results: %v", err)
+			}
+			break
+		}
+		if len(listings) == 0 {
+			break
+		}
+
+		for _, l := range listings {
+			if seen[l.CarId] || !c.Matches(l) || (skip != nil && skip(l.CarId)) {
+				continue
+			}
+			seen[l.CarId] = true
+			candidates = append(candidates, l)
+			if len(candidates) == maxCandidates {
+				break
+			}
+		}
+	}
+
+	urls := make([]string, len(candidates))
+	for i, l := range candidates {
+		urls[i] = l.URL
+	}
+	rows := batch.Process(ctx, urls, detailWorkers, profile, s.Lookup, nil)
+
+	var found Found
+	for i, row := range rows {
+		switch {
+		case row.Err != nil:
+		case c.MatchesResult(row.Result):
+			found.Cars = append(found.Cars, Car{Listing: candidates[i], Result: row.Result})
+		default:
+			found.Rejected = append(found.Rejected, candidates[i].CarId)
+		}
+	}
+	if failed := countFailed(rows); failed > 0 && failed == len(rows) {
+		return Found{}, fmt.Errorf("error while getting listings: %v", rows[0].Err)
+	}
+
+	sort.SliceStable(found.Cars, func(i, j int) bool {
+		return found.Cars[i].Result.Total < found.Cars[j].Result.Total
+	})
+	return found, nil
+}
+
+func countFailed(rows []batch.Row) int {
+	var failed int
+	for _, row := range rows {
+		if row.Err != nil {
+			failed++
+		}
+	}
+	return failed
+}
@@ -0,0 +1,131 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mashinki/parser"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testPage renders che168 search cards: id, price in 10k yuan, mileage in 10k km, registration date
+func testPage(cards ...[4]string) string {
+	var sb strings.Builder
+	sb.WriteString(`<html><body><ul class="viewlist_ul">`)
+	for _, c := range cards {
+		fmt.Fprintf(&sb, `<li class="cards-li list-photo-li" infoid="%s" carname="Car %s" price="%s" milage="%s" regdate="%s" dealerid="77">`+
+			`<a class="carinfo" href="/dealer/77/%s.html?pvareaid=1"></a></li>`, c[0], c[0], c[1], c[2], c[3], c[0])
+	}
+	sb.WriteString(`</ul></body></html>`)
+	return sb.String()
+}
+
+func TestParseCriteria(t *testing.T) {
+	c, err := ParseCriteria("brand=AODI model=aodia4l year=2019-2022 price=-200000 mileage=80000 city=beijing fuel=Бензин total=3000000")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := Criteria{Brand: "aodi", Model: "aodia4l", City: "beijing", YearFrom: 2019, YearTo: 2022, PriceTo: 200000, MaxMileage: 80000,
+		Fuel: "бензин", MaxTotal: 3000000}
+	if c != want {
+		t.Errorf("expected %+v, got %+v", want, c)
+	}
+
+	if parsed, err := ParseCriteria(c.String()); err != nil || parsed != c {
+		t.Errorf("criteria %q are not parsed back: %+v, %v", c.String(), parsed, err)
+	}
+
+	for _, text := range []string{"", "brand", "color=red", "year=2022-2019", "price=cheap"} {
+		if _, err := ParseCriteria(text); err == nil {
+			t.Errorf("expected error for %q", text)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	pages := map[int]string{
+		1: testPage(
+			[4]string{"1", "20", "3", "2021/5"},
+			[4]string{"2", "12", "1.5", "2022/1"},
+			[4]string{"3", "15", "12", "2020/3"}, // слишком большой пробег
+		),
+		2: testPage(
+			[4]string{"2", "12", "1.5", "2022/1"}, // повтор
+			[4]string{"4", "9", "2", "2016/8"},    // слишком старая
+			[4]string{"5", "16", "4", "2021/2"},
+		),
+	}
+
+	var queried []parser.SearchQuery
+	s := Searcher{
+		Listings: func(q parser.SearchQuery, page int) ([]parser.Listing, error) {
+			queried = append(queried, q)
+			return parser.ParseListings(pages[page])
+		},
+		Lookup: func(url string) (parser.CarInfo, error) {
+			if strings.HasSuffix(url, "/5.html") {
+				return parser.CarInfo{}, errors.New("not found")
+			}
+			id := strings.TrimSuffix(url[strings.LastIndex(url, "/")+1:], ".html")
+			prices := map[string]float64{"1": 200_000, "2": 120_000}
+			return parser.CarInfo{CarId: id, Price: prices[id], EngineSize: 1998, Year: "2021-05"}, nil
+		},
+	}
+
+	c := Criteria{Brand: "aodi", YearFrom: 2018, MaxMileage: 100_000}
+	cars, err := s.Search(context.Background(), c, 5, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(cars) != 2 || cars[0].Listing.CarId != "2" || cars[1].Listing.CarId != "1" {
+		t.Fatalf("expected cars 2 and 1 sorted by total, got %+v", cars)
+	}
+	if cars[0].Result.Total >= cars[1].Result.Total {
+		t.Errorf("cars are not sorted by landed cost")
+	}
+	if cars[0].Listing.URL != "https://www.che168.com/dealer/77/2.html" || cars[0].Listing.Mileage != 15_000 {
+		t.Errorf("unexpected listing: %+v", cars[0].Listing)
+	}
+	if len(queried) != 3 || queried[0].Brand != "aodi" {
+		t.Errorf("expected pages until an empty one, got %+v", queried)
+	}
+
+	if cars, _ = s.Search(context.Background(), c, 1, ""); len(cars) != 1 {
+		t.Errorf("expected limit to be applied, got %d cars", len(cars))
+	}
+
+	// already seen cars are not requested, the landed cost is checked after calculation
+	c.MaxTotal = cars[0].Result.Total
+	found, err := s.Find(context.Background(), c, "", func(carID string) bool { return carID == "1" })
+	if err != nil || len(found.Cars) != 1 || found.Cars[0].Listing.CarId != "2" {
+		t.Errorf("expected only car 2, got %+v, %v", found.Cars, err)
+	}
+	c.Fuel = "дизель"
+	if found, _ = s.Find(context.Background(), c, "", nil); len(found.Cars) != 0 || len(found.Rejected) != 2 {
+		t.Errorf("expected no diesel cars and two rejected, got %+v", found)
+	}
+
+	ids, err := s.ListingIDs(context.Background(), c)
+	if err != nil || len(ids) < 3 {
+		t.Errorf("expected ids of all listings, got %v, %v", ids, err)
+	}
+}
+
+func TestSearchURL(t *testing.T) {
+	if got := parser.SearchURL(parser.SearchQuery{Brand: "aodi", Model: "aodia4l"}, 2); got != "https://www.che168.com/china/aodi/aodia4l/a0_0msdgscncgpi1ltocsp2exx0/" {
+		t.Errorf("unexpected url %q", got)
+	}
+
+	c := Criteria{Brand: "aodi", City: "beijing", YearFrom: 2020, YearTo: 2023, PriceTo: 250_000, MaxMileage: 80_000}
+	q := c.query(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC))
+	if q.AgeFrom != 2 || q.AgeTo != 5 {
+		t.Errorf("unexpected ages %d-%d", q.AgeFrom, q.AgeTo)
+	}
+	want := "https://www.che168.com/beijing/aodi/0_25/a2_5m0_8sdgscncgpi1ltocsp1exx0/"
+	if got := parser.SearchURL(q, 1); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
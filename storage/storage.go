@@ -0,0 +1,83 @@
+// Package storage keeps bot data in JSON files
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// DefaultDir is used when DATA_DIR is not set
+const DefaultDir = "data"
+
+// File is a JSON file with one value. Writes replace the file atomically,
+// so a crash never leaves a half-written file.
+type File struct {
+	path string
+	mu   sync.Mutex
+}
+
+// Open returns the file in the directory, the file is created on the first Save
+func Open(dir, name string) *File {
+	return &File{path: filepath.Join(dir, name)}
+}
+
+// Path returns the path of the file
+func (f *File) Path() string {
+	return f.path
+}
+
+// Load decodes the file into v. A missing file is not an error and leaves v unchanged.
+func (f *File) Load(v interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error while reading %s: %v", f.path, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("error while parsing %s: %v", f.path, err)
+	}
+	return nil
+}
+
+// Save encodes v into the file
+func (f *File) Save(v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error while encoding %s: %v", f.path, err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+		return fmt.Errorf("error while creating data directory: %v", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("error while creating temporary file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error while writing %s: %v", f.path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error while writing %s: %v", f.path, err)
+	}
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return fmt.Errorf("error while replacing %s: %v", f.path, err)
+	}
+	return nil
+}
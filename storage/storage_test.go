@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFile(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "data")
+	f := Open(dir, "test.json")
+
+	value := map[string]int{"a": 1}
+	if err := f.Load(&value); err != nil || value["a"] != 1 {
+		t.Fatalf("missing file should leave the value unchanged: %v, %v", value, err)
+	}
+
+	if err := f.Save(map[string]int{"b": 2}); err != nil {
+		t.Fatalf("error while saving: %v", err)
+	}
+	var loaded map[string]int
+	if err := Open(dir, "test.json").Load(&loaded); err != nil || loaded["b"] != 2 {
+		t.Errorf("unexpected loaded value: %v, %v", loaded, err)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("temporary files are left: %v", entries)
+	}
+
+	if err := os.WriteFile(f.Path(), []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Load(&loaded); err == nil {
+		t.Errorf("expected error for broken file")
+	}
+}
@@ -0,0 +1,30 @@
+package taxes
+
+const (
+	byCustomsFee      = 120   // сбор за таможенное оформление, бел. рубли
+	byRecyclingNew    = 544.5 // утильсбор для физлиц, машины до 3 лет
+	byRecyclingOld    = 1_089 // утильсбор для физлиц, машины старше 3 лет
+	byRegistrationFee = 84    // госпошлина за регистрацию, 2 базовые величины
+)
+
+// belarus calculates payments in Belarus
+type belarus struct{}
+
+func (belarus) Code() string       { return "by" }
+func (belarus) Currency() Currency { return BYN }
+
+func (belarus) payments(c *fullCarInfo) []LineItem {
+	duty, dutyNote := c.customsDuty()
+
+	recycling := byRecyclingNew
+	if c.getCarAge() >= 3 {
+		recycling = byRecyclingOld
+	}
+
+	return []LineItem{
+		c.item(KindCustomsDuty, duty, EUR, dutyNote),
+		c.item(KindCustomsFee, byCustomsFee, BYN, note{}),
+		c.item(KindRecyclingFee, recycling, BYN, note{}),
+		c.item(KindRegistrationFee, byRegistrationFee, BYN, note{}),
+	}
+}
@@ -0,0 +1,116 @@
+package taxes
+
+import (
+	"errors"
+	"mashinki/parser"
+	"math"
+	"sort"
+)
+
+// ErrBudgetTooLow means that payments exceed the budget even for the cheapest car
+var ErrBudgetTooLow = errors.New("budget is lower than payments for the car")
+
+// BudgetResult is the most expensive listing that fits into the budget
+type BudgetResult struct {
+	Budget   float64 `json:"budget"`    // в рублях
+	MaxPrice float64 `json:"max_price"` // цена объявления в юанях
+	Result   Result  `json:"result"`    // расчет для максимальной цены
+
+	// цена упирается в границу категории: машина дороже попадает
+	// в следующую категорию и выходит за бюджет
+	AtBandLimit bool `json:"at_band_limit"`
+}
+
+// priceLimiter is implemented by countries with fees depending on the price
+type priceLimiter interface {
+	priceLimits(rates Rates) []float64
+}
+
+// MaxPrice finds the highest listing price in yuan with the landed cost within the budget in rubles.
+// Engine size, year and fuel type are taken from ci, the price is ignored.
+//
+// The landed cost grows with the price inside a tax band but jumps at band limits,
+// so a price just below a limit may fit while a price above it does not.
+// Every band is searched separately and the highest fitting price wins.
+func MaxPrice(budget float64, ci parser.CarInfo, profile string) (BudgetResult, error) {
+	p, ok := GetCostProfile(profile)
+	if !ok {
+		p, _ = GetCostProfile("")
+	}
+	rates := CurrentRates()
+
+	total := func(price float64) float64 {
+		ci.Price = price
+		fci := newFullCarInfo(ci, p, rates)
+		fci.calculate()
+		return fci.result().Total
+	}
+
+	// the car price alone must fit, so the budget converted to yuan is the upper bound
+	maxPrice := math.Floor(budget / rates.CNY)
+	limits := priceLimits(newFullCarInfo(ci, p, rates), maxPrice)
+
+	// bands are (limits[i-1], limits[i]], searching from the most expensive one
+	for i := len(limits) - 1; i >= 0; i-- {
+		lo := 1.0
+		if i > 0 {
+			lo = limits[i-1] + 1
+		}
+		hi := limits[i]
+		if lo > hi || total(lo) > budget {
+			continue
+		}
+
+		for lo < hi {
+			mid := math.Ceil((lo + hi) / 2)
+			if total(mid) <= budget {
+				lo = mid
+			} else {
+				hi = mid - 1
+			}
+		}
+
+		ci.Price = lo
+		return BudgetResult{
+			Budget:      budget,
+			MaxPrice:    lo,
+			Result:      calculate(ci, p),
+			AtBandLimit: lo == limits[i] && i < len(limits)-1,
+		}, nil
+	}
+
+	return BudgetResult{}, ErrBudgetTooLow
+}
+
+// priceLimits returns whole yuan prices where tax bands end, up to maxPrice which ends the last band
+func priceLimits(c *fullCarInfo, maxPrice float64) []float64 {
+	var prices []float64
+	if c.getCarAge() < 3 {
+		for _, r := range dutyRatesUnder3 {
+			if r.limit > 0 {
+				prices = append(prices, r.limit*c.rates.EUR/c.rates.CNY)
+			}
+		}
+	}
+	if l, ok := c.Jurisdiction.(priceLimiter); ok {
+		prices = append(prices, l.priceLimits(c.rates)...)
+	}
+
+	// limits are inclusive, so the last whole price of the band is rounded down
+	var limits []float64
+	for _, price := range prices {
+		if price = math.Floor(price); price >= 1 && price < maxPrice {
+			limits = append(limits, price)
+		}
+	}
+	limits = append(limits, maxPrice)
+	sort.Float64s(limits)
+
+	unique := limits[:0]
+	for _, l := range limits {
+		if len(unique) == 0 || unique[len(unique)-1] != l {
+			unique = append(unique, l)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,87 @@
+package taxes
+
+import (
+	"errors"
+	"mashinki/parser"
+	"math"
+	"testing"
+)
+
+func budgetCar(year string, engine int, price float64) parser.CarInfo {
+	var ci parser.CarInfo
+	ci.Year = year
+	ci.EngineSize = engine
+	ci.FuelType = "бензин"
+	ci.Price = price
+	return ci
+}
+
+func totalAt(ci parser.CarInfo, price float64) float64 {
+	ci.Price = price
+	return Calculate(ci, "").Total
+}
+
+func TestMaxPrice(t *testing.T) {
+	young := budgetCar("2024-01", 1998, 0)
+	// the first duty band of cars under 3 years ends at 8 500 euro
+	limit := math.Floor(dutyRatesUnder3[0].limit * EURRate / CNYRate)
+	if totalAt(young, limit+1) <= totalAt(young, limit) {
+		t.Fatalf("expected the landed cost to jump after %v yuan", limit)
+	}
+
+	tests := []struct {
+		name    string
+		ci      parser.CarInfo
+		budget  float64
+		want    float64 // 0 - only checked to be the highest fitting price
+		atLimit bool    // checked with want
+	}{
+		{"below the duty band jump", young, totalAt(young, limit+1) - 1, limit, true},
+		{"exactly the band limit", young, totalAt(young, limit), limit, true},
+		{"above the duty band jump", young, totalAt(young, limit+1), limit + 1, false},
+		{"under 3 years", budgetCar("2023-01", 1998, 0), 3_000_000, 0, false},
+		{"3 years", budgetCar("2022-01", 1998, 0), 3_000_000, 0, false},
+		{"engine at the band limit", budgetCar("2020-01", 3000, 0), 10_000_000, 0, false},
+		{"engine over the band limit", budgetCar("2020-01", 3001, 0), 10_000_000, 0, false},
+	}
+	found := make(map[string]float64)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := MaxPrice(tt.budget, tt.ci, "")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			found[tt.name] = r.MaxPrice
+
+			if tt.want > 0 && r.MaxPrice != tt.want {
+				t.Errorf("expected %v yuan, got %v", tt.want, r.MaxPrice)
+			}
+			if tt.want > 0 && r.AtBandLimit != tt.atLimit {
+				t.Errorf("expected at band limit %v, got %v", tt.atLimit, r.AtBandLimit)
+			}
+			if r.Result.Total > tt.budget || r.Result.Total != totalAt(tt.ci, r.MaxPrice) {
+				t.Errorf("returned price does not fit the budget: %v > %v", r.Result.Total, tt.budget)
+			}
+			if totalAt(tt.ci, r.MaxPrice+1) <= tt.budget {
+				t.Errorf("price %v + 1 also fits the budget", r.MaxPrice)
+			}
+		})
+	}
+
+	if found["engine over the band limit"] >= found["engine at the band limit"] {
+		t.Errorf("the recycling fee jump after 3000 cm³ should lower the price: %v", found)
+	}
+	if found["3 years"] == found["under 3 years"] {
+		t.Errorf("cars of 3 years should be taxed by the engine, not the price: %v", found)
+	}
+}
+
+func TestMaxPriceTooLow(t *testing.T) {
+	ci := budgetCar("2024-01", 1998, 0)
+	if _, err := MaxPrice(totalAt(ci, 1)-1, ci, ""); !errors.Is(err, ErrBudgetTooLow) {
+		t.Errorf("expected ErrBudgetTooLow, got %v", err)
+	}
+	if r, err := MaxPrice(totalAt(ci, 1), ci, ""); err != nil || r.MaxPrice != 1 {
+		t.Errorf("expected the minimum price, got %+v %v", r, err)
+	}
+}
@@ -0,0 +1,62 @@
+package taxes
+
+import (
+	"errors"
+	"fmt"
+	"mashinki/parser"
+	"strings"
+)
+
+// requiredFields are needed for any calculation, payments make no sense without them
+var requiredFields = []parser.Field{parser.FieldPrice, parser.FieldYear, parser.FieldEngineSize}
+
+// ErrInsufficientData is matched by InsufficientDataError with errors.Is
+var ErrInsufficientData = errors.New("not enough data to calculate")
+
+// InsufficientDataError lists required fields missing in the car
+type InsufficientDataError struct {
+	Missing []parser.Field
+}
+
+func (e *InsufficientDataError) Error() string {
+	names := make([]string, len(e.Missing))
+	for i, f := range e.Missing {
+		names[i] = string(f)
+	}
+	return fmt.Sprintf("%v: missing %s", ErrInsufficientData, strings.Join(names, ", "))
+}
+
+func (e *InsufficientDataError) Is(target error) bool {
+	return target == ErrInsufficientData
+}
+
+// Check returns issues of the fields used by the calculation.
+// Missing required fields also make an InsufficientDataError.
+func Check(ci parser.CarInfo) ([]parser.Issue, error) {
+	var issues []parser.Issue
+	var missing []parser.Field
+	for _, issue := range ci.Validate() {
+		for _, f := range requiredFields {
+			if issue.Field != f {
+				continue
+			}
+			issues = append(issues, issue)
+			if issue.Kind == parser.IssueMissing {
+				missing = append(missing, f)
+			}
+		}
+	}
+
+	if len(missing) > 0 {
+		return issues, &InsufficientDataError{Missing: missing}
+	}
+	return issues, nil
+}
+
+// CalculateChecked is Calculate refusing cars without the required fields
+func CalculateChecked(ci parser.CarInfo, profile string) (Result, error) {
+	if _, err := Check(ci); err != nil {
+		return Result{}, err
+	}
+	return Calculate(ci, profile), nil
+}
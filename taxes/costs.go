@@ -0,0 +1,155 @@
+package taxes
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"mashinki/i18n"
+	"os"
+	"sync"
+)
+
+//go:embed costs.json
+var defaultCostsConfig []byte
+
+// CostProfile describes all non-customs expenses for one delivery route
+type CostProfile struct {
+	Name             string             `json:"name"`
+	Title            string             `json:"title"`
+	Country          string             `json:"country"`               // страна растаможки, по умолчанию Россия
+	DealerCommission float64            `json:"dealer_commission_cny"` // комиссия дилера в Китае
+	ExportFee        float64            `json:"export_fee_cny"`        // экспортные расходы в Китае
+	Logistics        map[string]float64 `json:"logistics_rub"`         // доставка по городу отправления
+	BrokerFee        float64            `json:"broker_fee_rub"`        // услуги брокера
+	SBKTS            float64            `json:"sbkts_rub"`             // СБКТС
+	EPTS             float64            `json:"epts_rub"`              // ЭПТС
+	LabFee           float64            `json:"lab_fee_rub"`           // лаборатория
+}
+
+type costsConfig struct {
+	Default  string        `json:"default"`
+	Profiles []CostProfile `json:"profiles"`
+}
+
+var (
+	costs   costsConfig
+	costsMu sync.RWMutex
+)
+
+func init() {
+	if err := parseCostsConfig(defaultCostsConfig); err != nil {
+		panic("error while parsing default costs config: " + err.Error())
+	}
+}
+
+func parseCostsConfig(data []byte) error {
+	var cfg costsConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return fmt.Errorf("error while parsing JSON: %v", err)
+	}
+	if len(cfg.Profiles) == 0 {
+		return fmt.Errorf("no cost profiles found")
+	}
+	if cfg.Default == "" {
+		cfg.Default = cfg.Profiles[0].Name
+	}
+	hasDefault := false
+	for i, p := range cfg.Profiles {
+		j, ok := GetJurisdiction(p.Country)
+		if !ok {
+			return fmt.Errorf("unknown country %q of cost profile %q", p.Country, p.Name)
+		}
+		cfg.Profiles[i].Country = j.Code()
+		hasDefault = hasDefault || p.Name == cfg.Default
+	}
+	if !hasDefault {
+		return fmt.Errorf("default cost profile %q not found", cfg.Default)
+	}
+
+	// the config is replaced only if it is valid
+	costsMu.Lock()
+	costs = cfg
+	costsMu.Unlock()
+	return nil
+}
+
+// LoadCostProfiles replaces built-in cost profiles with the ones from the JSON file
+func LoadCostProfiles(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error while reading costs config: %v", err)
+	}
+	return parseCostsConfig(data)
+}
+
+// CostProfiles returns all available cost profiles
+func CostProfiles() []CostProfile {
+	costsMu.RLock()
+	defer costsMu.RUnlock()
+	return append([]CostProfile(nil), costs.Profiles...)
+}
+
+// GetCostProfile returns cost profile by its name.
+// Empty name means the default profile.
+func GetCostProfile(name string) (CostProfile, bool) {
+	costsMu.RLock()
+	defer costsMu.RUnlock()
+
+	if name == "" {
+		name = costs.Default
+	}
+	for _, p := range costs.Profiles {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return CostProfile{}, false
+}
+
+// CountryProfile returns the first cost profile of the country
+func CountryProfile(country string) (CostProfile, bool) {
+	costsMu.RLock()
+	defer costsMu.RUnlock()
+
+	for _, p := range costs.Profiles {
+		if p.Country == country {
+			return p, true
+		}
+	}
+	return CostProfile{}, false
+}
+
+// logistics returns delivery price from the origin city, falling back to the default route price
+func (p CostProfile) logistics(city string) float64 {
+	if price, ok := p.Logistics[city]; ok {
+		return price
+	}
+	return p.Logistics["default"]
+}
+
+// Items returns itemized expenses for a car shipped from the given city
+func (p CostProfile) Items(city string, rates Rates) []LineItem {
+	items := []LineItem{
+		{Code: "dealer_commission", Amount: p.DealerCommission * rates.CNY, Original: p.DealerCommission, Currency: CNY},
+		{Code: "export_fee", Amount: p.ExportFee * rates.CNY, Original: p.ExportFee, Currency: CNY},
+		{Code: "logistics", Amount: p.logistics(city), Currency: RUB},
+		{Code: "broker", Amount: p.BrokerFee, Currency: RUB},
+		{Code: "sbkts", Amount: p.SBKTS, Currency: RUB},
+		{Code: "epts", Amount: p.EPTS, Currency: RUB},
+		{Code: "lab", Amount: p.LabFee, Currency: RUB},
+	}
+
+	// Skipping expenses that are not used in the profile
+	result := items[:0]
+	for _, item := range items {
+		if item.Amount > 0 {
+			item.Kind = KindExpense
+			item.Name = i18n.T(i18n.Default, "item."+item.Code)
+			if item.Currency == RUB {
+				item.Original = item.Amount
+			}
+			result = append(result, item)
+		}
+	}
+	return result
+}
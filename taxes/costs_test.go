@@ -0,0 +1,29 @@
+package taxes
+
+import "testing"
+
+func TestParseCostsConfig(t *testing.T) {
+	defer parseCostsConfig(defaultCostsConfig)
+
+	before := CostProfiles()
+	for _, data := range []string{
+		`{`,
+		`{"profiles": []}`,
+		`{"default": "mars", "profiles": [{"name": "moscow"}]}`,
+		`{"profiles": [{"name": "moscow", "country": "xx"}]}`,
+	} {
+		if err := parseCostsConfig([]byte(data)); err == nil {
+			t.Errorf("expected error for %s", data)
+		}
+		if after := CostProfiles(); len(after) != len(before) || after[0].Name != before[0].Name {
+			t.Fatalf("invalid config %s replaced the working one: %+v", data, after)
+		}
+	}
+
+	if err := parseCostsConfig([]byte(`{"profiles": [{"name": "almaty", "country": "kz"}]}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p, ok := GetCostProfile(""); !ok || p.Name != "almaty" || p.Country != "kz" {
+		t.Errorf("expected the first profile to be the default, got %+v", p)
+	}
+}
@@ -0,0 +1,167 @@
+package taxes
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Jurisdiction calculates payments of the country the car is cleared in.
+// EAEU countries share the customs duty for individuals, other payments differ.
+type Jurisdiction interface {
+	Code() string       // ISO 3166-1 alpha-2 в нижнем регистре
+	Currency() Currency // национальная валюта
+	payments(c *fullCarInfo) []LineItem
+}
+
+// jurisdictions lists supported countries, Russia goes first as the default one
+var jurisdictions = []Jurisdiction{russia{}, kazakhstan{}, kyrgyzstan{}, belarus{}}
+
+// Jurisdictions returns all supported countries
+func Jurisdictions() []Jurisdiction {
+	return append([]Jurisdiction(nil), jurisdictions...)
+}
+
+// GetJurisdiction returns the country by its code.
+// Empty code means Russia.
+func GetJurisdiction(code string) (Jurisdiction, bool) {
+	if code == "" {
+		return russia{}, true
+	}
+	for _, j := range jurisdictions {
+		if j.Code() == strings.ToLower(code) {
+			return j, true
+		}
+	}
+	return nil, false
+}
+
+// dutyRate is a duty band for cars under 3 years by the price in euro
+type dutyRate struct {
+	limit    float64 // верхняя граница стоимости, 0 если ее нет
+	rate     float64 // доля от стоимости
+	minPerCC float64 // минимум, евро за см³
+}
+
+var dutyRatesUnder3 = []dutyRate{
+	{8500, 0.54, 2.5},
+	{16700, 0.48, 3.5},
+	{42300, 0.48, 5.5},
+	{84500, 0.48, 7.5},
+	{169000, 0.48, 15.0},
+	{0, 0.48, 20.0},
+}
+
+// dutyRateUnder3 returns the band of the price in euro
+func dutyRateUnder3(priceEUR float64) (int, dutyRate) {
+	for i, r := range dutyRatesUnder3 {
+		if r.limit == 0 || priceEUR <= r.limit {
+			return i, r
+		}
+	}
+	last := len(dutyRatesUnder3) - 1
+	return last, dutyRatesUnder3[last]
+}
+
+// пошлина для < 3 лет
+func (c *fullCarInfo) calculateCustomsDutyUnder3Years() (float64, note) {
+	priceEUR := c.CI.Price * c.rates.CNY / c.rates.EUR
+	engineSize := float64(c.CI.EngineSize)
+	_, r := dutyRateUnder3(priceEUR)
+
+	percentDuty := priceEUR * r.rate
+	minDuty := engineSize * r.minPerCC
+	dutyNote := note{"duty_percent", []string{fmt.Sprintf("%g", r.rate*100), fmt.Sprintf("%g", r.minPerCC)}}
+
+	if minDuty > percentDuty {
+		return minDuty, dutyNote
+	}
+	return percentDuty, dutyNote
+}
+
+// для (3-5) и (5+) лет
+func (c *fullCarInfo) calculateCustomsDutyOver3Years() (float64, note) {
+	engineSize := float64(c.CI.EngineSize)
+	age := c.getCarAge()
+	isOver5 := age > 5
+
+	var ratePerCC float64
+	switch {
+	case engineSize <= 1000:
+		ratePerCC = 3.0
+		if !isOver5 {
+			ratePerCC = 1.5
+		}
+	case engineSize <= 1500:
+		ratePerCC = 3.2
+		if !isOver5 {
+			ratePerCC = 1.7
+		}
+	case engineSize <= 1800:
+		ratePerCC = 3.5
+		if !isOver5 {
+			ratePerCC = 2.5
+		}
+	case engineSize <= 2300:
+		ratePerCC = 4.8
+		if !isOver5 {
+			ratePerCC = 2.7
+		}
+	case engineSize <= 3000:
+		ratePerCC = 5.0
+		if !isOver5 {
+			ratePerCC = 3.0
+		}
+	default:
+		ratePerCC = 5.7
+		if !isOver5 {
+			ratePerCC = 3.6
+		}
+	}
+
+	return engineSize * ratePerCC, note{"duty_per_cc", []string{fmt.Sprintf("%g", ratePerCC)}}
+}
+
+// Пошлина для всех возрастов в евро
+func (c *fullCarInfo) customsDuty() (float64, note) {
+	age := c.getCarAge()
+
+	if age < 3 {
+		return c.calculateCustomsDutyUnder3Years()
+	}
+	return c.calculateCustomsDutyOver3Years()
+}
+
+// taxBand describes which customs category the car falls into
+func (c *fullCarInfo) taxBand() Band {
+	age := c.getCarAge()
+
+	if age < 3 {
+		i, r := dutyRateUnder3(c.CI.Price * c.rates.CNY / c.rates.EUR)
+		band := Band{Age: "under_3", Basis: "price_eur", To: r.limit}
+		if i > 0 {
+			band.From = dutyRatesUnder3[i-1].limit
+		}
+		return band
+	}
+
+	band := Band{Age: "3_5", Basis: "engine_cc"}
+	if age > 5 {
+		band.Age = "over_5"
+	}
+
+	switch engineSize := c.CI.EngineSize; {
+	case engineSize <= 1000:
+		band.To = 1000
+	case engineSize <= 1500:
+		band.From, band.To = 1000, 1500
+	case engineSize <= 1800:
+		band.From, band.To = 1500, 1800
+	case engineSize <= 2300:
+		band.From, band.To = 1800, 2300
+	case engineSize <= 3000:
+		band.From, band.To = 2300, 3000
+	default:
+		band.From = 3000
+	}
+	return band
+}
@@ -0,0 +1,77 @@
+package taxes
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPayments(t *testing.T) {
+	// 100 000 ¥ = 1 100 000 ₽ = 11 000 €, the second duty band of cars under 3 years:
+	// 48%, at least 3.5 €/cm³ -> 1998 * 3.5 = 6 993 €
+	young := budgetCar("2024-01", 1998, 100_000)
+	// over 5 years, 1998 cm³: 4.8 €/cm³ -> 9 590.4 €
+	old := budgetCar("2019-01", 1998, 100_000)
+
+	tests := []struct {
+		country string
+		old     bool
+		want    map[ItemKind]float64 // в рублях
+	}{
+		{"ru", false, map[ItemKind]float64{KindCustomsDuty: 699_300, KindCustomsFee: 4_269, KindRecyclingFee: 3_400}},
+		{"ru", true, map[ItemKind]float64{KindCustomsDuty: 959_040, KindCustomsFee: 4_269, KindRecyclingFee: 5_200}},
+		// 20 000 ₸ fee, 250 МРП recycling, 0.25 МРП registration of a new car
+		{"kz", false, map[ItemKind]float64{KindCustomsDuty: 699_300, KindCustomsFee: 4_000,
+			KindRecyclingFee: 196_600, KindRegistrationFee: 196.6}},
+		{"kz", true, map[ItemKind]float64{KindCustomsDuty: 959_040, KindCustomsFee: 4_000,
+			KindRecyclingFee: 196_600, KindRegistrationFee: 393_200}},
+		{"kg", false, map[ItemKind]float64{KindCustomsDuty: 699_300, KindCustomsFee: 1_000, KindRegistrationFee: 4_400}},
+		{"kg", true, map[ItemKind]float64{KindCustomsDuty: 959_040, KindCustomsFee: 1_000, KindRegistrationFee: 4_400}},
+		{"by", false, map[ItemKind]float64{KindCustomsDuty: 699_300, KindCustomsFee: 3_600,
+			KindRecyclingFee: 16_335, KindRegistrationFee: 2_520}},
+		{"by", true, map[ItemKind]float64{KindCustomsDuty: 959_040, KindCustomsFee: 3_600,
+			KindRecyclingFee: 32_670, KindRegistrationFee: 2_520}},
+	}
+	for _, tt := range tests {
+		ci := young
+		if tt.old {
+			ci = old
+		}
+		c := newFullCarInfo(ci, CostProfile{Country: tt.country}, defaultRates())
+		items := c.Jurisdiction.payments(c)
+
+		got := make(map[ItemKind]float64)
+		for _, item := range items {
+			got[item.Kind] += item.Amount
+			if math.Abs(defaultRates().ToRub(item.Original, item.Currency)-item.Amount) > 0.01 {
+				t.Errorf("%s: %s amount does not match %v %s", tt.country, item.Kind, item.Original, item.Currency)
+			}
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%s, old %v: expected items %v, got %v", tt.country, tt.old, tt.want, got)
+		}
+		for kind, want := range tt.want {
+			if math.Abs(got[kind]-want) > 0.01 {
+				t.Errorf("%s, old %v: expected %s %v, got %v", tt.country, tt.old, kind, want, got[kind])
+			}
+		}
+	}
+}
+
+func TestRussiaTotal(t *testing.T) {
+	// the default profile keeps the Russian calculation of earlier versions
+	ci := budgetCar("2024-01", 1998, 100_000)
+	r := Calculate(ci, "")
+	if r.Country != "ru" {
+		t.Fatalf("expected Russia by default, got %q", r.Country)
+	}
+
+	p, _ := GetCostProfile("")
+	var expenses float64
+	for _, item := range p.Items("", defaultRates()) {
+		expenses += item.Amount
+	}
+	want := 1_100_000 + 699_300 + 4_269 + 3_400 + expenses
+	if math.Abs(r.Total-want) > 0.01 {
+		t.Errorf("expected total %v, got %v", want, r.Total)
+	}
+}
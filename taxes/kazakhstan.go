@@ -0,0 +1,63 @@
+package taxes
+
+import "fmt"
+
+const (
+	kzMCI             = 3_932  // МРП на 2025 год, тенге
+	kzCustomsFee      = 20_000 // сбор за таможенное оформление, тенге
+	kzRecyclingBase   = 50     // базовая ставка утильплатежа, МРП
+	kzNewCarAge       = 2      // до этого возраста регистрация почти бесплатная
+	kzRegistrationMax = 500    // регистрация машин старше 3 лет, МРП
+)
+
+// kazakhstan calculates payments in Kazakhstan,
+// fees are set in monthly calculation indices (МРП)
+type kazakhstan struct{}
+
+func (kazakhstan) Code() string       { return "kz" }
+func (kazakhstan) Currency() Currency { return KZT }
+
+func (k kazakhstan) payments(c *fullCarInfo) []LineItem {
+	duty, dutyNote := c.customsDuty()
+	recycling := k.recyclingFee(c)
+	registration := k.registrationFee(c)
+
+	return []LineItem{
+		c.item(KindCustomsDuty, duty, EUR, dutyNote),
+		c.item(KindCustomsFee, kzCustomsFee, KZT, note{}),
+		c.item(KindRecyclingFee, recycling*kzMCI, KZT, mciNote(recycling)),
+		c.item(KindRegistrationFee, registration*kzMCI, KZT, mciNote(registration)),
+	}
+}
+
+// утильплатеж в МРП
+func (kazakhstan) recyclingFee(c *fullCarInfo) float64 {
+	var coef float64
+	switch engineSize := c.CI.EngineSize; {
+	case engineSize <= 1000:
+		coef = 3
+	case engineSize <= 2000:
+		coef = 5
+	case engineSize <= 3000:
+		coef = 7
+	default:
+		coef = 15
+	}
+	return kzRecyclingBase * coef
+}
+
+// первичная регистрация в МРП
+func (kazakhstan) registrationFee(c *fullCarInfo) float64 {
+	switch age := c.getCarAge(); {
+	case age < kzNewCarAge:
+		return 0.25
+	case age <= 3:
+		return 50
+	default:
+		return kzRegistrationMax
+	}
+}
+
+func mciNote(mci float64) note {
+	return note{"mci", []string{fmt.Sprintf("%g", mci), Grouped.Format(kzMCI)}}
+}
@@ -0,0 +1,22 @@
+package taxes
+
+const (
+	kgCustomsFee      = 1_000 // сбор за таможенное оформление, сом
+	kgRegistrationFee = 4_400 // регистрация и номера, сом
+)
+
+// kyrgyzstan calculates payments in Kyrgyzstan, there is no recycling fee for individuals
+type kyrgyzstan struct{}
+
+func (kyrgyzstan) Code() string       { return "kg" }
+func (kyrgyzstan) Currency() Currency { return KGS }
+
+func (kyrgyzstan) payments(c *fullCarInfo) []LineItem {
+	duty, dutyNote := c.customsDuty()
+
+	return []LineItem{
+		c.item(KindCustomsDuty, duty, EUR, dutyNote),
+		c.item(KindCustomsFee, kgCustomsFee, KGS, note{}),
+		c.item(KindRegistrationFee, kgRegistrationFee, KGS, note{}),
+	}
+}
@@ -0,0 +1,67 @@
+package taxes
+
+import (
+	"errors"
+	"sync"
+)
+
+// Rates are exchange rates to rubles used in the calculation
+type Rates struct {
+	CNY float64 `json:"cny"`
+	EUR float64 `json:"eur"`
+	KZT float64 `json:"kzt"`
+	KGS float64 `json:"kgs"`
+	BYN float64 `json:"byn"`
+}
+
+var (
+	rates   = defaultRates()
+	ratesMu sync.RWMutex
+)
+
+func defaultRates() Rates {
+	return Rates{CNY: CNYRate, EUR: EURRate, KZT: KZTRate, KGS: KGSRate, BYN: BYNRate}
+}
+
+// ToRub converts the amount in the currency to rubles
+func (r Rates) ToRub(amount float64, currency Currency) float64 {
+	switch currency {
+	case CNY:
+		return amount * r.CNY
+	case EUR:
+		return amount * r.EUR
+	case KZT:
+		return amount * r.KZT
+	case KGS:
+		return amount * r.KGS
+	case BYN:
+		return amount * r.BYN
+	}
+	return amount
+}
+
+// CurrentRates returns exchange rates used in new calculations
+func CurrentRates() Rates {
+	ratesMu.RLock()
+	defer ratesMu.RUnlock()
+	return rates
+}
+
+// SetRates overrides exchange rates used in new calculations
+func SetRates(r Rates) error {
+	if r.CNY <= 0 || r.EUR <= 0 || r.KZT <= 0 || r.KGS <= 0 || r.BYN <= 0 {
+		return errors.New("rates must be positive")
+	}
+
+	ratesMu.Lock()
+	rates = r
+	ratesMu.Unlock()
+	return nil
+}
+
+// ResetRates returns the default exchange rates
+func ResetRates() {
+	ratesMu.Lock()
+	rates = defaultRates()
+	ratesMu.Unlock()
+}
@@ -0,0 +1,211 @@
+package taxes
+
+import (
+	"mashinki/i18n"
+	"mashinki/parser"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Currency is an ISO 4217 currency code
+type Currency string
+
+const (
+	RUB Currency = "RUB"
+	CNY Currency = "CNY"
+	EUR Currency = "EUR"
+	KZT Currency = "KZT"
+	KGS Currency = "KGS"
+	BYN Currency = "BYN"
+)
+
+// ItemKind tells what a line item of the result is
+type ItemKind string
+
+const (
+	KindCarPrice        ItemKind = "car_price"
+	KindCustomsDuty     ItemKind = "customs_duty"
+	KindCustomsFee      ItemKind = "customs_fee"
+	KindRecyclingFee    ItemKind = "recycling_fee"
+	KindRegistrationFee ItemKind = "registration_fee" // регистрация в стране ввоза
+	KindExpense         ItemKind = "expense"          // доставка и оформление
+)
+
+// LineItem is one payment of the result
+type LineItem struct {
+	Kind     ItemKind `json:"kind"`
+	Code     string   `json:"code"` // ключ названия для перевода
+	Name     string   `json:"name"`
+	Amount   float64  `json:"amount"`   // в рублях
+	Original float64  `json:"original"` // в исходной валюте
+	Currency Currency `json:"currency"` // исходная валюта
+	Note     string   `json:"note,omitempty"`
+
+	// tariff note to be translated
+	NoteKey  string   `json:"-"`
+	NoteArgs []string `json:"-"`
+}
+
+// Band is the customs category of the car
+type Band struct {
+	Age   string  `json:"age"`   // under_3, 3_5 или over_5
+	Basis string  `json:"basis"` // price_eur или engine_cc
+	From  float64 `json:"from"`
+	To    float64 `json:"to"` // 0 если верхней границы нет
+}
+
+// Result is a complete calculation of the landed cost of a car
+type Result struct {
+	Car          parser.CarInfo `json:"car"`
+	Country      string         `json:"country"` // страна растаможки
+	Age          int            `json:"age"`
+	TaxBand      string         `json:"tax_band"`
+	Band         Band           `json:"band"`
+	Profile      string         `json:"profile"`
+	ProfileTitle string         `json:"profile_title"`
+	Rates        Rates          `json:"rates"`
+	Items        []LineItem     `json:"items"`
+	Total        float64        `json:"total"` // в рублях
+
+	// пропущенные и подозрительные значения, от которых зависит расчет
+	Warnings []parser.Issue `json:"warnings,omitempty"`
+}
+
+// result collects calculated payments into the Result
+func (c *fullCarInfo) result() Result {
+	items := []LineItem{c.item(KindCarPrice, c.CI.Price, CNY, note{})}
+	items = append(items, c.payments...)
+	items = append(items, c.otherCosts...)
+
+	var total float64
+	for _, item := range items {
+		total += item.Amount
+	}
+
+	r := Result{
+		Car:          *c.CI,
+		Country:      c.Jurisdiction.Code(),
+		Age:          c.getCarAge(),
+		Band:         c.taxBand(),
+		Profile:      c.Profile.Name,
+		ProfileTitle: c.Profile.Title,
+		Rates:        c.rates,
+		Items:        items,
+		Total:        total,
+	}
+	return r.Localize(i18n.Default)
+}
+
+// Localize returns a copy of the result with item names, notes and the tax band in the language
+func (r Result) Localize(lang i18n.Lang) Result {
+	items := make([]LineItem, len(r.Items))
+	for i, item := range r.Items {
+		if item.Code != "" {
+			item.Name = i18n.T(lang, "item."+item.Code)
+		}
+		if item.NoteKey != "" {
+			args := make([]interface{}, len(item.NoteArgs))
+			for j, arg := range item.NoteArgs {
+				args[j] = arg
+			}
+			item.Note = i18n.T(lang, "note."+item.NoteKey, args...)
+		}
+		items[i] = item
+	}
+	r.Items = items
+	r.TaxBand = r.Band.Text(lang)
+	return r
+}
+
+// Text describes the band: "до 3 лет, стоимость 8 500 – 16 700 €"
+func (b Band) Text(lang i18n.Lang) string {
+	if b.Age == "" {
+		return ""
+	}
+
+	var limits string
+	switch {
+	case b.From == 0:
+		limits = i18n.T(lang, "band.up_to", Grouped.Format(b.To))
+	case b.To == 0:
+		limits = i18n.T(lang, "band.over", Grouped.Format(b.From))
+	default:
+		limits = i18n.T(lang, "band.range", Grouped.Format(b.From), Grouped.Format(b.To))
+	}
+
+	unit := "€"
+	if b.Basis == "engine_cc" {
+		unit = i18n.T(lang, "unit.cc")
+	}
+	return i18n.T(lang, "band."+b.Age) + ", " + i18n.T(lang, "band."+b.Basis) + " " + limits + " " + unit
+}
+
+// item creates a line item from the amount in the original currency
+func (c *fullCarInfo) item(kind ItemKind, original float64, currency Currency, n note) LineItem {
+	return LineItem{
+		Kind:     kind,
+		Code:     string(kind),
+		Amount:   c.rates.ToRub(original, currency),
+		Original: original,
+		Currency: currency,
+		NoteKey:  n.key,
+		NoteArgs: n.args,
+	}
+}
+
+// Amount returns the sum of all items of the kind
+func (r Result) Amount(kind ItemKind) float64 {
+	var sum float64
+	for _, item := range r.Items {
+		if item.Kind == kind {
+			sum += item.Amount
+		}
+	}
+	return sum
+}
+
+// ItemsOf returns all items of the kind
+func (r Result) ItemsOf(kind ItemKind) []LineItem {
+	var items []LineItem
+	for _, item := range r.Items {
+		if item.Kind == kind {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// NumberFormat formats amounts of results the same way in every frontend
+type NumberFormat struct {
+	Decimals int
+	Group    string // разделитель разрядов, пусто - без разделителя
+}
+
+var (
+	// Grouped is for people: 1 200 000
+	Grouped = NumberFormat{Group: " "}
+	// Plain is for files and machines: 1200000.00
+	Plain = NumberFormat{Decimals: 2}
+)
+
+// Format formats the number
+func (f NumberFormat) Format(v float64) string {
+	s := strconv.FormatFloat(math.Abs(v), 'f', f.Decimals, 64)
+	digits, fraction, _ := strings.Cut(s, ".")
+
+	var sb strings.Builder
+	if v < 0 && strings.Trim(s, "0.") != "" {
+		sb.WriteByte('-')
+	}
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			sb.WriteString(f.Group)
+		}
+		sb.WriteRune(d)
+	}
+	if fraction != "" {
+		sb.WriteString("." + fraction)
+	}
+	return sb.String()
+}
@@ -0,0 +1,80 @@
+package taxes
+
+import (
+	"encoding/json"
+	"mashinki/i18n"
+	"strings"
+	"testing"
+)
+
+func TestNumberFormat(t *testing.T) {
+	tests := []struct {
+		f    NumberFormat
+		v    float64
+		want string
+	}{
+		{Grouped, 1_200_000, "1 200 000"},
+		{Grouped, 999.6, "1 000"},
+		{Grouped, -120_000, "-120 000"},
+		{Grouped, 0, "0"},
+		{Plain, 1_200_000.5, "1200000.50"},
+		{Plain, -0.001, "0.00"},
+		{NumberFormat{Decimals: 2, Group: " "}, 12_345.678, "12 345.68"},
+	}
+	for _, tt := range tests {
+		if got := tt.f.Format(tt.v); got != tt.want {
+			t.Errorf("%+v.Format(%v): expected %q, got %q", tt.f, tt.v, tt.want, got)
+		}
+	}
+}
+
+func TestResult(t *testing.T) {
+	p := CostProfile{Name: "test", Title: "Test", Logistics: map[string]float64{"default": 100_000, "beijing": 150_000}, BrokerFee: 50_000}
+	ci := budgetCar("2024-01", 1998, 100_000)
+	ci.Seller.City = "beijing"
+
+	c := newFullCarInfo(ci, p, defaultRates())
+	c.calculate()
+	r := c.result()
+
+	if r.Items[0].Kind != KindCarPrice || r.Items[0].Amount != 1_100_000 || r.Items[0].Original != 100_000 || r.Items[0].Currency != CNY {
+		t.Errorf("the car price should go first, got %+v", r.Items[0])
+	}
+
+	var sum float64
+	for _, item := range r.Items {
+		sum += item.Amount
+	}
+	if r.Total != sum {
+		t.Errorf("total %v is not the sum of items %v", r.Total, sum)
+	}
+	if got := r.Amount(KindExpense); got != 200_000 {
+		t.Errorf("expected expenses with the logistics of the seller city, got %v", got)
+	}
+	if got := r.ItemsOf(KindCustomsDuty); len(got) != 1 || got[0].Amount != 699_300 {
+		t.Errorf("unexpected duty items %+v", got)
+	}
+	if r.Band != (Band{Age: "under_3", Basis: "price_eur", From: 8_500, To: 16_700}) || r.Profile != "test" || r.Age != 1 {
+		t.Errorf("unexpected result %+v", r)
+	}
+
+	en := r.Localize(i18n.English)
+	duty := en.ItemsOf(KindCustomsDuty)[0]
+	if duty.Name != "Customs duty" || duty.Note != "48%, at least 3.5 €/cm³" {
+		t.Errorf("unexpected localized item %+v", duty)
+	}
+	if en.TaxBand != "under 3 years, value 8 500 – 16 700 €" {
+		t.Errorf("unexpected band %q", en.TaxBand)
+	}
+	if r.ItemsOf(KindCustomsDuty)[0].Name == duty.Name {
+		t.Errorf("Localize should not change the original result")
+	}
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "NoteKey") || !strings.Contains(string(data), `"kind":"customs_duty"`) {
+		t.Errorf("unexpected json %s", data)
+	}
+}
@@ -0,0 +1,102 @@
+package taxes
+
+import "fmt"
+
+// russia calculates payments for the unified rate of individuals in Russia
+type russia struct{}
+
+func (russia) Code() string       { return "ru" }
+func (russia) Currency() Currency { return RUB }
+
+func (r russia) payments(c *fullCarInfo) []LineItem {
+	duty, dutyNote := c.customsDuty()
+	fee, feeNote := r.customsFee(c)
+	recycling, recyclingNote := r.recyclingFee(c)
+
+	return []LineItem{
+		c.item(KindCustomsDuty, duty, EUR, dutyNote),
+		c.item(KindCustomsFee, fee, RUB, feeNote),
+		c.item(KindRecyclingFee, recycling, RUB, recyclingNote),
+	}
+}
+
+// Утиль сбор
+func (russia) recyclingFee(c *fullCarInfo) (float64, note) {
+	age := c.getCarAge()
+	isNew := age < 3
+	engineSize := c.CI.EngineSize
+
+	var coef float64
+	switch {
+	case engineSize <= 1000:
+		coef = 0.17
+		if !isNew {
+			coef = 0.26
+		}
+	case engineSize <= 2000:
+		coef = 0.17
+		if !isNew {
+			coef = 0.26
+		}
+	case engineSize <= 3000:
+		coef = 0.17
+		if !isNew {
+			coef = 0.26
+		}
+	case engineSize <= 3500:
+		if isNew {
+			coef = 107.67
+		} else {
+			coef = 165.84
+		}
+	default:
+		if isNew {
+			coef = 137.11
+		} else {
+			coef = 180.24
+		}
+	}
+
+	return BaseUtilFee * coef, note{"recycling", []string{Grouped.Format(BaseUtilFee), fmt.Sprintf("%g", coef)}}
+}
+
+// customsFees are fees for customs clearance by the price in rubles
+var customsFees = []struct {
+	limit float64 // верхняя граница стоимости, 0 если ее нет
+	fee   float64
+}{
+	{200_000, 1_067},
+	{450_000, 2_134},
+	{1_200_000, 4_269},
+	{2_700_000, 11_746},
+	{4_200_000, 16_524},
+	{5_500_000, 21_344},
+	{7_000_000, 27_540},
+	{0, 30_000},
+}
+
+// таможка
+func (russia) customsFee(c *fullCarInfo) (float64, note) {
+	priceRub := c.CI.Price * c.rates.CNY
+
+	for i, f := range customsFees {
+		if f.limit == 0 {
+			return f.fee, note{"fee_over", []string{Grouped.Format(customsFees[i-1].limit)}}
+		}
+		if priceRub <= f.limit {
+			return f.fee, note{"fee_up_to", []string{Grouped.Format(f.limit)}}
+		}
+	}
+	return 0, note{}
+}
+
+// priceLimits returns listing prices in yuan where the customs fee changes
+func (russia) priceLimits(rates Rates) []float64 {
+	var limits []float64
+	for _, f := range customsFees {
+		if f.limit > 0 {
+			limits = append(limits, f.limit/rates.CNY)
+		}
+	}
+	return limits
+}
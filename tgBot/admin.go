@@ -0,0 +1,464 @@
+package tgBot
+
+import (
+	"fmt"
+	envhandler "mashinki/envHandler"
+	"mashinki/i18n"
+	"mashinki/logging"
+	"mashinki/parser"
+	"mashinki/ratelimit"
+	"mashinki/storage"
+	"mashinki/taxes"
+	"mashinki/translations"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	cmdAdmin     = "admin"
+	cmdStats     = "stats"
+	cmdBroadcast = "broadcast"
+	cmdBan       = "ban"
+	cmdUnban     = "unban"
+	cmdSetRate   = "setrate"
+	cmdReload    = "reload"
+	cmdHealth    = "health"
+	cmdWhitelist = "whitelist"
+	cmdAllow     = "allow"
+	cmdDeny      = "deny"
+
+	statsDays = 7
+)
+
+// access keeps admins and the whitelist of a private deployment.
+// Changes made by admin commands are saved to the file.
+type access struct {
+	mu            sync.RWMutex
+	file          *storage.File // nil если изменения не сохраняются
+	admins        map[int64]bool
+	whitelist     map[int64]bool
+	whitelistMode bool
+	whitelistSet  bool                // whitelist изменён командами и сохраняется в файл
+	bans          map[int64]time.Time // до какого времени, нулевое - навсегда
+}
+
+// accessData is the saved state of admin commands
+type accessData struct {
+	WhitelistSet  bool                `json:"whitelist_set,omitempty"`
+	Whitelist     []int64             `json:"whitelist,omitempty"`
+	WhitelistMode bool                `json:"whitelist_mode,omitempty"`
+	Bans          map[int64]time.Time `json:"bans,omitempty"`
+}
+
+// newAccess reads ADMIN_IDS, WHITELIST and WHITELIST_MODE from the environment.
+// The saved whitelist and whitelist mode replace the environment ones once admins changed them,
+// saved bans alone keep the environment whitelist.
+func newAccess(file *storage.File) (*access, error) {
+	a := &access{
+		admins:        parseIDs(envhandler.GetEnv("ADMIN_IDS")),
+		whitelist:     parseIDs(envhandler.GetEnv("WHITELIST")),
+		whitelistMode: envhandler.GetEnv("WHITELIST_MODE") == "true",
+	}
+	if err := a.load(file); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// load restores the state saved in the file, changes are saved to it from now on
+func (a *access) load(file *storage.File) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.file = file
+	if a.whitelist == nil {
+		a.whitelist = make(map[int64]bool)
+	}
+	a.bans = make(map[int64]time.Time)
+
+	var saved *accessData
+	if err := file.Load(&saved); err != nil {
+		return fmt.Errorf("error while loading access: %v", err)
+	}
+	if saved != nil {
+		if saved.WhitelistSet {
+			a.whitelist = make(map[int64]bool)
+			for _, id := range saved.Whitelist {
+				a.whitelist[id] = true
+			}
+			a.whitelistMode = saved.WhitelistMode
+			a.whitelistSet = true
+		}
+		now := time.Now()
+		for id, until := range saved.Bans {
+			if until.IsZero() || until.After(now) {
+				a.bans[id] = until
+			}
+		}
+	}
+	return nil
+}
+
+// save writes the state, the caller holds the lock
+func (a *access) save() error {
+	if a.file == nil {
+		return nil
+	}
+
+	data := accessData{Bans: a.bans}
+	if a.whitelistSet {
+		data.WhitelistSet, data.WhitelistMode = true, a.whitelistMode
+		for id := range a.whitelist {
+			data.Whitelist = append(data.Whitelist, id)
+		}
+		sort.Slice(data.Whitelist, func(i, j int) bool { return data.Whitelist[i] < data.Whitelist[j] })
+	}
+	return a.file.Save(data)
+}
+
+// setWhitelistMode turns the whitelist on or off and saves it
+func (a *access) setWhitelistMode(on bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.whitelistMode = on
+	a.whitelistSet = true
+	return a.save()
+}
+
+// setAllowed adds the user to the whitelist or removes from it and saves it
+func (a *access) setAllowed(userID int64, allow bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if allow {
+		a.whitelist[userID] = true
+	} else {
+		delete(a.whitelist, userID)
+	}
+	a.whitelistSet = true
+	return a.save()
+}
+
+// ban remembers the ban until the time, zero time means forever
+func (a *access) ban(userID int64, until time.Time) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.bans[userID] = until
+	return a.save()
+}
+
+func (a *access) unban(userID int64) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.bans, userID)
+	return a.save()
+}
+
+// applyBans bans saved users in the limiter after a restart
+func (a *access) applyBans(limiter *ratelimit.Limiter, now time.Time) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	for id, until := range a.bans {
+		switch {
+		case until.IsZero():
+			limiter.Ban(id, 0)
+		case until.After(now):
+			limiter.Ban(id, until.Sub(now))
+		}
+	}
+}
+
+// parseIDs parses comma separated Telegram user IDs
+func parseIDs(s string) map[int64]bool {
+	ids := make(map[int64]bool)
+	for _, part := range strings.Split(s, ",") {
+		if id, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64); err == nil {
+			ids[id] = true
+		}
+	}
+	return ids
+}
+
+func (a *access) isAdmin(userID int64) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.admins[userID]
+}
+
+// adminIDs returns Telegram IDs of all admins
+func (a *access) adminIDs() []int64 {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	ids := make([]int64, 0, len(a.admins))
+	for id := range a.admins {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// allowed tells whether the user may use the bot
+func (a *access) allowed(userID int64) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return !a.whitelistMode || a.admins[userID] || a.whitelist[userID]
+}
+
+// updateSender returns the user who sent the update
+func updateSender(update tgbotapi.Update) *tgbotapi.User {
+	switch {
+	case update.Message != nil:
+		return update.Message.From
+	case update.CallbackQuery != nil:
+		return update.CallbackQuery.From
+	}
+	return nil
+}
+
+// checkAccess tells whether the update should be processed in whitelist mode
+func (b *Bot) checkAccess(update tgbotapi.Update) bool {
+	user := updateSender(update)
+	if user == nil || b.access.allowed(user.ID) {
+		return true
+	}
+
+	if chatID, ok := updateUserID(update); ok {
+		go b.sendText(chatID, i18n.T(b.lang(chatID), "access.private"))
+	}
+	return false
+}
+
+// handleAdminCommand executes admin commands. Returns false if the message is not one.
+func (b *Bot) handleAdminCommand(message *tgbotapi.Message) bool {
+	if message.From == nil || !b.access.isAdmin(message.From.ID) {
+		return false
+	}
+
+	chatID := message.Chat.ID
+	lang := b.lang(chatID)
+	args := strings.Fields(message.CommandArguments())
+
+	switch message.Command() {
+	case cmdAdmin:
+		b.sendText(chatID, i18n.T(lang, "admin.help"))
+	case cmdStats:
+		b.sendText(chatID, b.stats.report(statsDays, lang))
+	case cmdBroadcast:
+		b.broadcast(chatID, message.CommandArguments())
+	case cmdBan:
+		b.banUser(chatID, args)
+	case cmdUnban:
+		b.unbanUser(chatID, args)
+	case cmdSetRate:
+		b.setRates(chatID, args)
+	case cmdReload:
+		b.reloadConfig(chatID)
+	case cmdHealth:
+		b.sendText(chatID, healthReport(lang))
+	case cmdWhitelist:
+		b.setWhitelistMode(chatID, args)
+	case cmdAllow, cmdDeny:
+		b.editWhitelist(chatID, args, message.Command() == cmdAllow)
+	default:
+		return false
+	}
+	return true
+}
+
+// broadcast sends the text to all users who wrote to the bot since its start
+func (b *Bot) broadcast(chatID int64, text string) {
+	lang := b.lang(chatID)
+	text = strings.TrimSpace(text)
+	if text == "" {
+		b.sendText(chatID, i18n.T(lang, "admin.broadcast_usage"))
+		return
+	}
+
+	b.statesMutex.RLock()
+	chats := make([]int64, 0, len(b.userStates))
+	for id := range b.userStates {
+		chats = append(chats, id)
+	}
+	b.statesMutex.RUnlock()
+
+	var sent int
+	for _, id := range chats {
+		if _, err := b.api.Send(tgbotapi.NewMessage(id, "📢 "+text)); err != nil {
+			logging.DefaultLogger.LogErrorF("Error broadcasting to %d: %v", id, err)
+			continue
+		}
+		sent++
+		// Telegram allows about 30 messages per second
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	b.sendText(chatID, i18n.T(lang, "admin.broadcast_sent", sent, len(chats)))
+}
+
+// adminReply sends the reply and warns if the change was not saved
+func (b *Bot) adminReply(chatID int64, text string, saveErr error) {
+	if saveErr != nil {
+		logging.DefaultLogger.LogErrorF("Error saving access: %v", saveErr)
+		text += "\n" + i18n.T(b.lang(chatID), "admin.not_saved", saveErr)
+	}
+	b.sendText(chatID, text)
+}
+
+func (b *Bot) banUser(chatID int64, args []string) {
+	lang := b.lang(chatID)
+	if len(args) == 0 {
+		b.sendText(chatID, i18n.T(lang, "admin.ban_usage"))
+		return
+	}
+	userID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		b.sendText(chatID, i18n.T(lang, "admin.invalid_id"))
+		return
+	}
+
+	var duration time.Duration
+	var until time.Time
+	if len(args) > 1 {
+		minutes, err := strconv.Atoi(args[1])
+		if err != nil || minutes <= 0 {
+			b.sendText(chatID, i18n.T(lang, "admin.invalid_duration"))
+			return
+		}
+		duration = time.Duration(minutes) * time.Minute
+		until = time.Now().Add(duration)
+	}
+
+	b.limiter.Ban(userID, duration)
+	b.adminReply(chatID, i18n.T(lang, "admin.banned", userID), b.access.ban(userID, until))
+}
+
+func (b *Bot) unbanUser(chatID int64, args []string) {
+	lang := b.lang(chatID)
+	if len(args) == 0 {
+		b.sendText(chatID, i18n.T(lang, "admin.unban_usage"))
+		return
+	}
+	userID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		b.sendText(chatID, i18n.T(lang, "admin.invalid_id"))
+		return
+	}
+
+	b.limiter.Unban(userID)
+	b.adminReply(chatID, i18n.T(lang, "admin.unbanned", userID), b.access.unban(userID))
+}
+
+func (b *Bot) setRates(chatID int64, args []string) {
+	lang := b.lang(chatID)
+	if len(args) == 1 && args[0] == "reset" {
+		taxes.ResetRates()
+	} else {
+		if len(args) != 2 && len(args) != 5 {
+			b.sendText(chatID, i18n.T(lang, "admin.setrate_usage"))
+			return
+		}
+
+		rates := taxes.CurrentRates()
+		fields := []*float64{&rates.CNY, &rates.EUR, &rates.KZT, &rates.KGS, &rates.BYN}
+		for i, arg := range args {
+			v, err := strconv.ParseFloat(strings.ReplaceAll(arg, ",", "."), 64)
+			if err != nil {
+				b.sendText(chatID, i18n.T(lang, "admin.invalid_rates"))
+				return
+			}
+			*fields[i] = v
+		}
+		if err := taxes.SetRates(rates); err != nil {
+			b.sendText(chatID, "❌ "+err.Error())
+			return
+		}
+	}
+
+	rates := taxes.CurrentRates()
+	b.sendText(chatID, i18n.T(lang, "admin.rates", rates.CNY, rates.EUR, rates.KZT, rates.KGS, rates.BYN))
+}
+
+// reloadConfig rereads files set in COSTS_CONFIG and GLOSSARY
+func (b *Bot) reloadConfig(chatID int64) {
+	lang := b.lang(chatID)
+	var report []string
+
+	if path := envhandler.GetEnv("COSTS_CONFIG"); path != "" {
+		if err := taxes.LoadCostProfiles(path); err != nil {
+			report = append(report, i18n.T(lang, "admin.costs_failed", err))
+		} else {
+			report = append(report, i18n.T(lang, "admin.costs_reloaded"))
+		}
+	} else {
+		report = append(report, i18n.T(lang, "admin.costs_not_set"))
+	}
+
+	if path := envhandler.GetEnv("GLOSSARY"); path != "" {
+		if err := translations.LoadGlossary(path); err != nil {
+			report = append(report, i18n.T(lang, "admin.glossary_failed", err))
+		} else {
+			report = append(report, i18n.T(lang, "admin.glossary_reloaded"))
+		}
+	} else {
+		report = append(report, i18n.T(lang, "admin.glossary_not_set"))
+	}
+
+	b.sendText(chatID, strings.Join(report, "\n"))
+}
+
+// healthReport checks all external services
+func healthReport(lang i18n.Lang) string {
+	checks := []struct {
+		name  string
+		check func() error
+	}{
+		{i18n.T(lang, "admin.health_proxy"), parser.CheckProxy},
+		{"che168.com", parser.CheckHealth},
+		{i18n.T(lang, "admin.health_translator"), translations.CheckHealth},
+	}
+
+	lines := []string{i18n.T(lang, "admin.health")}
+	for _, c := range checks {
+		start := time.Now()
+		if err := c.check(); err != nil {
+			lines = append(lines, fmt.Sprintf("❌ %s: %v", c.name, err))
+		} else {
+			lines = append(lines, i18n.T(lang, "admin.health_ok", c.name, time.Since(start).Milliseconds()))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (b *Bot) setWhitelistMode(chatID int64, args []string) {
+	lang := b.lang(chatID)
+	if len(args) != 1 || (args[0] != "on" && args[0] != "off") {
+		b.sendText(chatID, i18n.T(lang, "admin.whitelist_usage"))
+		return
+	}
+
+	on := args[0] == "on"
+	err := b.access.setWhitelistMode(on)
+	if on {
+		b.adminReply(chatID, i18n.T(lang, "admin.whitelist_on"), err)
+	} else {
+		b.adminReply(chatID, i18n.T(lang, "admin.whitelist_off"), err)
+	}
+}
+
+func (b *Bot) editWhitelist(chatID int64, args []string, allow bool) {
+	lang := b.lang(chatID)
+	if len(args) != 1 {
+		b.sendText(chatID, i18n.T(lang, "admin.allow_usage"))
+		return
+	}
+	userID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		b.sendText(chatID, i18n.T(lang, "admin.invalid_id"))
+		return
+	}
+
+	b.adminReply(chatID, i18n.T(lang, "admin.whitelist_updated", userID), b.access.setAllowed(userID, allow))
+}
@@ -0,0 +1,89 @@
+package tgBot
+
+import (
+	"mashinki/i18n"
+	"mashinki/ratelimit"
+	"mashinki/storage"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAccessSaved(t *testing.T) {
+	file := storage.Open(t.TempDir(), "access.json")
+	a := &access{admins: map[int64]bool{1: true}}
+	if err := a.load(file); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := a.setWhitelistMode(true); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.setAllowed(7, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.ban(8, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.ban(9, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.ban(10, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.unban(10); err != nil {
+		t.Fatal(err)
+	}
+
+	// after a restart
+	a = &access{admins: map[int64]bool{1: true}}
+	if err := a.load(file); err != nil {
+		t.Fatal(err)
+	}
+	if !a.whitelistMode || !a.allowed(7) || a.allowed(42) {
+		t.Errorf("whitelist is not restored: %+v", a)
+	}
+
+	limiter := ratelimit.NewLimiter(ratelimit.DefaultConfig())
+	a.applyBans(limiter, time.Now())
+	for id, banned := range map[int64]bool{8: true, 9: true, 10: false, 42: false} {
+		if got := limiter.Allow(id) == ratelimit.Banned; got != banned {
+			t.Errorf("user %d: expected banned %v", id, banned)
+		}
+	}
+}
+
+func TestBansKeepEnvWhitelist(t *testing.T) {
+	file := storage.Open(t.TempDir(), "access.json")
+	a := &access{whitelist: map[int64]bool{7: true}, whitelistMode: true}
+	if err := a.load(file); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.ban(8, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// WHITELIST is changed in .env before the restart
+	a = &access{whitelist: map[int64]bool{42: true}, whitelistMode: true}
+	if err := a.load(file); err != nil {
+		t.Fatal(err)
+	}
+	if !a.allowed(42) || a.allowed(7) {
+		t.Errorf("environment whitelist is replaced by a saved ban: %+v", a.whitelist)
+	}
+	if _, banned := a.bans[8]; !banned {
+		t.Errorf("ban is not restored")
+	}
+}
+
+func TestAdminReplies(t *testing.T) {
+	b := &Bot{userStates: make(map[int64]*UserState), stats: newBotStats()}
+	for _, lang := range i18n.Languages {
+		if text := b.stats.report(statsDays, lang); !strings.HasPrefix(text, i18n.T(lang, "admin.stats")) {
+			t.Errorf("%s: unexpected report %q", lang, text)
+		}
+	}
+	if en := i18n.T(i18n.English, "admin.help"); strings.ContainsAny(en, "абвгд") {
+		t.Errorf("admin help is not translated: %q", en)
+	}
+}
@@ -0,0 +1,73 @@
+package tgBot
+
+import (
+	"errors"
+	"fmt"
+	"mashinki/i18n"
+	"mashinki/logging"
+	"mashinki/parser"
+	"mashinki/render"
+	"mashinki/taxes"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const cmdBudget = "budget"
+
+// findMaxPrice answers /budget <бюджет> <объём> <год> [топливо]
+// with the most expensive listing price that fits into the budget
+func (b *Bot) findMaxPrice(chatID int64, state *UserState, args string) tgbotapi.MessageConfig {
+	lang := b.lang(chatID)
+
+	budget, carInfo, err := parseBudgetArgs(args)
+	if err != nil {
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "budget.usage"))
+		msg.ReplyMarkup = mainKeyboard(lang)
+		return msg
+	}
+
+	var msg tgbotapi.MessageConfig
+	result, err := taxes.MaxPrice(budget, carInfo, state.CostProfile)
+	switch {
+	case errors.Is(err, taxes.ErrBudgetTooLow):
+		msg = tgbotapi.NewMessage(chatID, i18n.T(lang, "budget.too_low"))
+	case err != nil:
+		logging.DefaultLogger.LogErrorF("Error finding max price: %v", err)
+		msg = tgbotapi.NewMessage(chatID, i18n.T(lang, "budget.usage"))
+	default:
+		msg = tgbotapi.NewMessage(chatID, render.BudgetMarkdown(result, lang))
+		msg.ParseMode = "Markdown"
+	}
+	msg.ReplyMarkup = mainKeyboard(lang)
+	return msg
+}
+
+// parseBudgetArgs parses "3000000 1998 2021 бензин"
+func parseBudgetArgs(args string) (float64, parser.CarInfo, error) {
+	fields := strings.Fields(args)
+	if len(fields) < 3 {
+		return 0, parser.CarInfo{}, errors.New("not enough arguments")
+	}
+
+	budget, err := strconv.ParseFloat(strings.ReplaceAll(fields[0], "_", ""), 64)
+	if err != nil || budget <= 0 {
+		return 0, parser.CarInfo{}, fmt.Errorf("invalid budget %q", fields[0])
+	}
+	engineSize, err := strconv.Atoi(fields[1])
+	if err != nil || engineSize <= 0 || engineSize > 10_000 {
+		return 0, parser.CarInfo{}, fmt.Errorf("invalid engine size %q", fields[1])
+	}
+	year, err := strconv.Atoi(fields[2])
+	if err != nil || year < 1950 || year > time.Now().Year() {
+		return 0, parser.CarInfo{}, fmt.Errorf("invalid year %q", fields[2])
+	}
+
+	return budget, parser.CarInfo{
+		EngineSize: engineSize,
+		Year:       fmt.Sprintf("%d-01", year),
+		FuelType:   strings.Join(fields[3:], " "),
+	}, nil
+}
@@ -0,0 +1,39 @@
+package tgBot
+
+import (
+	"mashinki/i18n"
+	"strings"
+	"testing"
+)
+
+func TestParseBudgetArgs(t *testing.T) {
+	budget, ci, err := parseBudgetArgs("3_000_000 1998 2021 бензин")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if budget != 3_000_000 || ci.EngineSize != 1998 || ci.Year != "2021-01" || ci.FuelType != "бензин" {
+		t.Errorf("unexpected arguments: %v %+v", budget, ci)
+	}
+
+	for _, args := range []string{"", "3000000 1998", "много 1998 2021", "3000000 0 2021", "3000000 1998 1900"} {
+		if _, _, err := parseBudgetArgs(args); err == nil {
+			t.Errorf("expected error for %q", args)
+		}
+	}
+}
+
+func TestFindMaxPrice(t *testing.T) {
+	b := &Bot{userStates: make(map[int64]*UserState)}
+
+	msg := b.findMaxPrice(42, b.getUserState(42), "3000000 1998 2024")
+	if !strings.Contains(msg.Text, i18n.T(i18n.Default, "budget.max_price")) || !strings.Contains(msg.Text, "⚠️") {
+		t.Errorf("unexpected answer:\n%s", msg.Text)
+	}
+
+	if msg = b.findMaxPrice(42, b.getUserState(42), "100000 1998 2024"); msg.Text != i18n.T(i18n.Default, "budget.too_low") {
+		t.Errorf("expected too low budget, got %q", msg.Text)
+	}
+	if msg = b.findMaxPrice(42, b.getUserState(42), "3000000"); msg.Text != i18n.T(i18n.Default, "budget.usage") {
+		t.Errorf("expected usage, got %q", msg.Text)
+	}
+}
@@ -0,0 +1,150 @@
+package tgBot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mashinki/batch"
+	"mashinki/bulk"
+	"mashinki/i18n"
+	"mashinki/logging"
+	"mashinki/parser"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	bulkWorkers       = 5
+	maxBulkFileSize   = 5 << 20
+	bulkProgressEvery = 3 * time.Second
+)
+
+// processBulkFile calculates all listings from the uploaded file and sends back a table
+func (b *Bot) processBulkFile(ctx context.Context, chatID int64, doc *tgbotapi.Document, profile string) {
+	lang := b.lang(chatID)
+
+	ext := strings.ToLower(filepath.Ext(doc.FileName))
+	if ext != ".txt" && ext != ".csv" && ext != ".xlsx" {
+		b.sendText(chatID, i18n.T(lang, "bulk.unsupported"))
+		return
+	}
+	if doc.FileSize > maxBulkFileSize {
+		b.sendText(chatID, i18n.T(lang, "bulk.too_big"))
+		return
+	}
+
+	data, err := b.downloadFile(doc.FileID)
+	if err != nil {
+		logging.DefaultLogger.LogErrorF("Error downloading file: %v", err)
+		b.sendText(chatID, i18n.T(lang, "bulk.download_error"))
+		return
+	}
+
+	urls, err := bulk.ReadURLs(doc.FileName, data)
+	switch {
+	case errors.Is(err, bulk.ErrNoURLs):
+		b.sendText(chatID, i18n.T(lang, "bulk.no_links"))
+		return
+	case errors.Is(err, bulk.ErrTooManyURLs):
+		b.sendText(chatID, i18n.T(lang, "bulk.too_many_links", bulk.MaxURLs))
+		return
+	case err != nil:
+		logging.DefaultLogger.LogErrorF("Error reading bulk file: %v", err)
+		b.sendText(chatID, i18n.T(lang, "bulk.read_error"))
+		return
+	}
+
+	progressMsg, err := b.api.Send(tgbotapi.NewMessage(chatID, i18n.T(lang, "bulk.progress", 0, len(urls))))
+	if err != nil {
+		logging.DefaultLogger.LogErrorF("Error sending progress message: %v", err)
+	}
+
+	// Editing the progress message not too often to stay within Telegram limits
+	var (
+		mu         sync.Mutex
+		lastUpdate time.Time
+		shown      int
+	)
+	progress := func(done, total int) {
+		mu.Lock()
+		if progressMsg.MessageID == 0 || done <= shown || (done < total && time.Since(lastUpdate) < bulkProgressEvery) {
+			mu.Unlock()
+			return
+		}
+		lastUpdate = time.Now()
+		shown = done
+		mu.Unlock()
+
+		// Sending without holding the lock so other workers are not blocked by Telegram
+		edit := tgbotapi.NewEditMessageText(chatID, progressMsg.MessageID, i18n.T(lang, "bulk.progress", done, total))
+		if _, err := b.api.Send(edit); err != nil {
+			logging.DefaultLogger.LogErrorF("Error updating progress: %v", err)
+		}
+	}
+
+	lookup := func(url string) (parser.CarInfo, error) {
+		return parser.GetCarInfoIn(url, lang.TranslationTarget())
+	}
+	rows := batch.Process(ctx, urls, bulkWorkers, profile, lookup, progress)
+
+	var failed int
+	var cars []parser.CarInfo
+	for _, r := range rows {
+		b.stats.record(chatID, r.Err)
+		if r.Err != nil {
+			failed++
+			logging.DefaultLogger.LogErrorF("Error processing %s: %v", r.URL, r.Err)
+			continue
+		}
+		cars = append(cars, r.Result.Car)
+	}
+	b.recordHistory(cars...)
+
+	// Answering in the same format as the uploaded file
+	var name string
+	if ext == ".xlsx" {
+		data, err = bulk.WriteXLSX(rows, lang)
+		name = "result.xlsx"
+	} else {
+		data, err = bulk.WriteCSV(rows, lang)
+		name = "result.csv"
+	}
+	if err != nil {
+		logging.DefaultLogger.LogErrorF("Error writing bulk result: %v", err)
+		b.sendText(chatID, i18n.T(lang, "bulk.write_error"))
+		return
+	}
+
+	result := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{Name: name, Bytes: data})
+	result.Caption = i18n.T(lang, "bulk.done", len(rows)-failed, len(rows), failed)
+	if _, err := b.api.Send(result); err != nil {
+		logging.DefaultLogger.LogErrorF("Error sending bulk result: %v", err)
+	}
+}
+
+// downloadFile gets the contents of a file uploaded to Telegram
+func (b *Bot) downloadFile(fileID string) ([]byte, error) {
+	fileURL, err := b.api.GetFileDirectURL(fileID)
+	if err != nil {
+		return nil, fmt.Errorf("error while getting file url: %v", err)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(fileURL)
+	if err != nil {
+		return nil, fmt.Errorf("error while downloading file: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("server returned status %d instead of 200 OK", resp.StatusCode)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, maxBulkFileSize))
+}
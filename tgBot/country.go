@@ -0,0 +1,63 @@
+package tgBot
+
+import (
+	"mashinki/i18n"
+	"mashinki/render"
+	"mashinki/taxes"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	cmdCountry = "country"
+
+	callbackCountry          = "country:"
+	callbackCompareCountries = "country_cmp:"
+)
+
+// countryKeyboard offers all supported countries of clearance
+func countryKeyboard(lang i18n.Lang) tgbotapi.InlineKeyboardMarkup {
+	var row []tgbotapi.InlineKeyboardButton
+	for _, j := range taxes.Jurisdictions() {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "country."+j.Code()), callbackCountry+j.Code()))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(row)
+}
+
+// selectCountry switches user's route to the first route of the country or offers the list of countries
+func (b *Bot) selectCountry(chatID int64, state *UserState, code string) tgbotapi.MessageConfig {
+	lang := b.lang(chatID)
+
+	if profile, ok := taxes.CountryProfile(strings.ToLower(strings.TrimSpace(code))); ok {
+		state.CostProfile = profile.Name
+		b.setUserState(chatID, state)
+
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "country.selected", i18n.T(lang, "country."+profile.Country), profile.Title))
+		msg.ReplyMarkup = mainKeyboard(lang)
+		return msg
+	}
+
+	current, _ := taxes.GetCostProfile(state.CostProfile)
+	msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "country.prompt", i18n.T(lang, "country."+current.Country)))
+	msg.ReplyMarkup = countryKeyboard(lang)
+	return msg
+}
+
+// compareCountries calculates the last car of the user in all countries
+func (b *Bot) compareCountries(chatID int64, carID string) tgbotapi.MessageConfig {
+	lang := b.lang(chatID)
+
+	state := b.getUserState(chatID)
+	if state.LastResult == nil || state.LastResult.Car.CarId != carID {
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "country.no_result"))
+		msg.ReplyMarkup = mainKeyboard(lang)
+		return msg
+	}
+
+	results := taxes.CompareCountries(state.LastResult.Car)
+	msg := tgbotapi.NewMessage(chatID, render.CompareCountriesMarkdown(results, lang))
+	msg.ParseMode = "Markdown"
+	msg.ReplyMarkup = mainKeyboard(lang)
+	return msg
+}
@@ -0,0 +1,147 @@
+package tgBot
+
+import (
+	"errors"
+	"fmt"
+	"mashinki/i18n"
+	"mashinki/parser"
+	"mashinki/render"
+	"mashinki/taxes"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	cmdFill      = "fill"
+	callbackFill = "fill:"
+)
+
+// fillKeys are /fill keys of car fields
+var fillKeys = map[string]parser.Field{
+	"price":   parser.FieldPrice,
+	"year":    parser.FieldYear,
+	"engine":  parser.FieldEngineSize,
+	"power":   parser.FieldPower,
+	"mileage": parser.FieldMileage,
+	"fuel":    parser.FieldFuelType,
+	"drive":   parser.FieldDrive,
+	"name":    parser.FieldName,
+}
+
+// fillPair is one "key=value" of /fill
+type fillPair struct {
+	key   string
+	value string
+}
+
+// parseFillArgs splits "name=Audi A4L engine=1998" into pairs,
+// words without "=" belong to the value before them
+func parseFillArgs(args string) []fillPair {
+	var pairs []fillPair
+	for _, word := range strings.Fields(args) {
+		key, value, ok := strings.Cut(word, "=")
+		if !ok && len(pairs) > 0 {
+			pairs[len(pairs)-1].value += " " + word
+			continue
+		}
+		pairs = append(pairs, fillPair{key: key, value: value})
+	}
+	return pairs
+}
+
+// fillCar completes the car waiting for data or the last calculated car with values entered by the user
+// like "engine=1998 power=150" and calculates it again
+func (b *Bot) fillCar(chatID int64, state *UserState, args string) tgbotapi.MessageConfig {
+	lang := b.lang(chatID)
+	reply := func(text string) tgbotapi.MessageConfig {
+		msg := tgbotapi.NewMessage(chatID, text)
+		msg.ReplyMarkup = mainKeyboard(lang)
+		return msg
+	}
+
+	var car parser.CarInfo
+	switch {
+	case state.PendingCar != nil:
+		car = *state.PendingCar
+	case state.LastResult != nil:
+		car = state.LastResult.Car
+	default:
+		return reply(i18n.T(lang, "fill.no_car"))
+	}
+	if strings.TrimSpace(args) == "" {
+		return reply(i18n.T(lang, "fill.usage"))
+	}
+
+	for _, pair := range parseFillArgs(args) {
+		f, ok := fillKeys[strings.ToLower(pair.key)]
+		if !ok {
+			return reply(i18n.T(lang, "fill.usage"))
+		}
+		if err := car.SetField(f, pair.value); err != nil {
+			return reply(i18n.T(lang, "fill.invalid", pair.key+"="+pair.value))
+		}
+	}
+
+	result, err := taxes.CalculateChecked(car, state.CostProfile)
+	if err != nil {
+		state.PendingCar = &car
+		b.setUserState(chatID, state)
+		return reply(insufficientText(err, lang))
+	}
+
+	state.PendingCar = nil
+	state.LastResult = &result
+	b.setUserState(chatID, state)
+
+	msg := tgbotapi.NewMessage(chatID, "✅ "+render.Markdown(result, lang)+issuesText(result.Car, lang))
+	msg.ParseMode = "Markdown"
+	msg.ReplyMarkup = quoteKeyboard(result.Car, lang)
+	return msg
+}
+
+// fillHelp answers the fill button of the car, only the car waiting for data or the last calculated one can be filled
+func (b *Bot) fillHelp(chatID int64, carID string) string {
+	state := b.getUserState(chatID)
+	lang := b.lang(chatID)
+
+	var current string
+	switch {
+	case state.PendingCar != nil:
+		current = state.PendingCar.CarId
+	case state.LastResult != nil:
+		current = state.LastResult.Car.CarId
+	}
+	if current == "" || current != carID {
+		return i18n.T(lang, "quote.outdated")
+	}
+	return i18n.T(lang, "fill.usage")
+}
+
+// insufficientText names the fields missing for the calculation
+func insufficientText(err error, lang i18n.Lang) string {
+	var insufficient *taxes.InsufficientDataError
+	if !errors.As(err, &insufficient) {
+		return i18n.T(lang, "lookup.error")
+	}
+	names := make([]string, len(insufficient.Missing))
+	for i, f := range insufficient.Missing {
+		names[i] = i18n.T(lang, "field."+string(f))
+	}
+	return i18n.T(lang, "fill.insufficient", strings.Join(names, ", "))
+}
+
+// issuesText is appended to the result if the car has data-quality issues
+func issuesText(car parser.CarInfo, lang i18n.Lang) string {
+	if text := render.IssuesMarkdown(car, lang); text != "" {
+		return "\n\n" + text
+	}
+	return ""
+}
+
+// fillButton is shown under results of cars with missing or suspicious fields
+func fillButton(carID string, lang i18n.Lang) []tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "btn.fill"), fmt.Sprintf("%s%s", callbackFill, carID)),
+	)
+}
@@ -0,0 +1,115 @@
+package tgBot
+
+import (
+	"mashinki/i18n"
+	"mashinki/parser"
+	"mashinki/taxes"
+	"strings"
+	"testing"
+)
+
+// completeCar has every field within reasonable limits
+func completeCar(id string) parser.CarInfo {
+	return parser.CarInfo{
+		CarId:      id,
+		FullName:   "Audi A4L",
+		Price:      150_000,
+		Year:       "2021-05",
+		EngineSize: 1984,
+		Power:      140,
+		Mileage:    parser.ParseMileage("3万公里"),
+		FuelType:   "Бензин",
+		Drive:      "Передний привод",
+	}
+}
+
+func TestFillCar(t *testing.T) {
+	b := &Bot{userStates: make(map[int64]*UserState)}
+
+	if msg := b.fillCar(1, b.getUserState(1), "engine=1998"); msg.Text != i18n.T(i18n.Russian, "fill.no_car") {
+		t.Errorf("expected no car reply, got %q", msg.Text)
+	}
+
+	car := parser.CarInfo{CarId: "1"}
+	car.SetSource(parser.FieldPrice, parser.SourceScraped)
+	car.Price = 150_000
+	b.setUserState(1, &UserState{PendingCar: &car})
+
+	if msg := b.fillCar(1, b.getUserState(1), ""); msg.Text != i18n.T(i18n.Russian, "fill.usage") {
+		t.Errorf("expected usage, got %q", msg.Text)
+	}
+	if msg := b.fillCar(1, b.getUserState(1), "engine=много"); !strings.Contains(msg.Text, "engine=много") {
+		t.Errorf("expected invalid value reply, got %q", msg.Text)
+	}
+
+	msg := b.fillCar(1, b.getUserState(1), "engine=1998")
+	if !strings.Contains(msg.Text, i18n.T(i18n.Russian, "field.year")) || strings.Contains(msg.Text, i18n.T(i18n.Russian, "field.engine_size")) {
+		t.Errorf("expected only the year to be missing, got %q", msg.Text)
+	}
+	state := b.getUserState(1)
+	if state.PendingCar == nil || state.PendingCar.EngineSize != 1998 {
+		t.Fatalf("filled values should be kept: %+v", state.PendingCar)
+	}
+
+	msg = b.fillCar(1, state, "year=2021")
+	state = b.getUserState(1)
+	if state.PendingCar != nil || state.LastResult == nil {
+		t.Fatalf("the car should be calculated")
+	}
+	if !strings.Contains(msg.Text, "✏️") {
+		t.Errorf("expected fields entered by hand in the result, got %q", msg.Text)
+	}
+	if s, _ := state.LastResult.Car.Source(parser.FieldYear); s != parser.SourceUser {
+		t.Errorf("expected year entered by the user, got %q", s)
+	}
+}
+
+func TestFillText(t *testing.T) {
+	b := &Bot{userStates: make(map[int64]*UserState)}
+	car := completeCar("1")
+	b.setUserState(1, &UserState{PendingCar: &car})
+
+	msg := b.fillCar(1, b.getUserState(1), "name=Audi A4L *new* fuel=Бензин_АИ-95 drive=4x4")
+	car = b.getUserState(1).LastResult.Car
+	if car.FullName != "Audi A4L *new*" || car.FuelType != "Бензин_АИ-95" || car.Drive != "4x4" {
+		t.Fatalf("unexpected filled car %+v", car)
+	}
+	if !strings.Contains(msg.Text, `Audi A4L \*new\*`) || !strings.Contains(msg.Text, `Бензин\_АИ-95`) {
+		t.Errorf("entered text should be escaped in Markdown, got %q", msg.Text)
+	}
+}
+
+func TestFillHelp(t *testing.T) {
+	b := &Bot{userStates: make(map[int64]*UserState)}
+	if text := b.fillHelp(1, "1"); text != i18n.T(i18n.Russian, "quote.outdated") {
+		t.Errorf("expected outdated without a car, got %q", text)
+	}
+
+	result := taxes.Calculate(completeCar("1"), "")
+	b.setUserState(1, &UserState{LastResult: &result})
+	if text := b.fillHelp(1, "2"); text != i18n.T(i18n.Russian, "quote.outdated") {
+		t.Errorf("expected outdated for another car, got %q", text)
+	}
+	if text := b.fillHelp(1, "1"); text != i18n.T(i18n.Russian, "fill.usage") {
+		t.Errorf("expected usage for the current car, got %q", text)
+	}
+}
+
+func TestInsufficientText(t *testing.T) {
+	_, err := taxes.CalculateChecked(parser.CarInfo{Sources: map[parser.Field]parser.Source{}}, "")
+	text := insufficientText(err, i18n.English)
+	for _, f := range []parser.Field{parser.FieldPrice, parser.FieldYear, parser.FieldEngineSize} {
+		if !strings.Contains(text, i18n.T(i18n.English, "field."+string(f))) {
+			t.Errorf("expected %s in %q", f, text)
+		}
+	}
+}
+
+func TestFillButton(t *testing.T) {
+	if keyboard := quoteKeyboard(completeCar("1"), i18n.Russian); len(keyboard.InlineKeyboard) != 2 {
+		t.Errorf("complete cars should have no fill button")
+	}
+	if keyboard := quoteKeyboard(parser.CarInfo{CarId: "1", Price: 150_000}, i18n.Russian); len(keyboard.InlineKeyboard) != 3 {
+		t.Errorf("incomplete cars should have the fill button")
+	}
+}
@@ -0,0 +1,92 @@
+package tgBot
+
+import (
+	"bytes"
+	"fmt"
+	"mashinki/history"
+	"mashinki/i18n"
+	"mashinki/logging"
+	"mashinki/parser"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const cmdPriceHistory = "pricehistory"
+
+// recordHistory saves snapshots of looked-up cars
+func (b *Bot) recordHistory(cars ...parser.CarInfo) {
+	if b.history == nil || len(cars) == 0 {
+		return
+	}
+	if err := b.history.Record(time.Now(), cars...); err != nil {
+		logging.DefaultLogger.LogErrorF("Error saving price history: %v", err)
+	}
+}
+
+// sendPriceHistory sends the price chart of the listing and its history in CSV
+func (b *Bot) sendPriceHistory(chatID int64, args string) {
+	lang := b.lang(chatID)
+
+	carID := parser.CarIDFromURL(strings.TrimSpace(args))
+	if carID == "" || b.history == nil {
+		b.sendText(chatID, i18n.T(lang, "history.usage"))
+		return
+	}
+
+	listing := b.history.Listing(carID)
+	if len(listing) == 0 {
+		b.sendText(chatID, i18n.T(lang, "history.empty"))
+		return
+	}
+	last := listing[len(listing)-1].Car
+	spec := b.history.Spec(last.SpecID)
+
+	chart, err := history.Chart(listing, spec, history.ChartLabels{
+		Title:   last.FullName,
+		Listing: i18n.T(lang, "history.listing"),
+		Spec:    i18n.T(lang, "history.spec"),
+		Price:   i18n.T(lang, "history.price"),
+	})
+	if err != nil {
+		logging.DefaultLogger.LogErrorF("Error drawing price history: %v", err)
+		b.sendText(chatID, i18n.T(lang, "history.error"))
+		return
+	}
+
+	photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileBytes{Name: carID + ".png", Bytes: chart})
+	photo.Caption = historySummary(listing, len(spec), lang)
+	if _, err := b.api.Send(photo); err != nil {
+		logging.DefaultLogger.LogErrorF("Error sending price history: %v", err)
+		return
+	}
+
+	// the export has all listings of the spec, the listing itself is among them
+	rows := spec
+	if len(rows) == 0 {
+		rows = listing
+	}
+	var buf bytes.Buffer
+	if err := history.WriteCSV(&buf, rows); err != nil {
+		logging.DefaultLogger.LogErrorF("Error writing price history: %v", err)
+		return
+	}
+	doc := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{Name: fmt.Sprintf("pricehistory_%s.csv", carID), Bytes: buf.Bytes()})
+	if _, err := b.api.Send(doc); err != nil {
+		logging.DefaultLogger.LogErrorF("Error sending price history: %v", err)
+	}
+}
+
+// historySummary describes the price change of the listing since the first lookup
+func historySummary(listing []history.Snapshot, specSnapshots int, lang i18n.Lang) string {
+	first, last := listing[0], listing[len(listing)-1]
+	text := i18n.T(lang, "history.summary", len(listing), first.At.Format("2006-01-02"), first.Car.Price, last.Car.Price)
+	if first.Car.Price > 0 && last.Car.Price != first.Car.Price {
+		text += fmt.Sprintf(" (%+.1f%%)", (last.Car.Price-first.Car.Price)/first.Car.Price*100)
+	}
+	if specSnapshots > len(listing) {
+		text += "\n" + i18n.T(lang, "history.spec_count", specSnapshots-len(listing))
+	}
+	return text
+}
@@ -0,0 +1,30 @@
+package tgBot
+
+import (
+	"mashinki/history"
+	"mashinki/i18n"
+	"mashinki/parser"
+	"mashinki/storage"
+	"testing"
+	"time"
+)
+
+func TestHistorySummary(t *testing.T) {
+	store, err := history.NewStore(storage.Open(t.TempDir(), "history.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	store.Record(start, parser.CarInfo{CarId: "1", SpecID: "42", Price: 200_000}, parser.CarInfo{CarId: "2", SpecID: "42", Price: 190_000})
+	store.Record(start.Add(time.Hour), parser.CarInfo{CarId: "1", SpecID: "42", Price: 180_000})
+
+	listing := store.Listing("1")
+	want := "📈 Запросов: 2 с 2025-05-01\nЦена: ¥200000 → ¥180000 (-10.0%)\nЦен других объявлений этой комплектации: 1"
+	if got := historySummary(listing, len(store.Spec("42")), i18n.Russian); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	if got := historySummary(listing[:1], 1, i18n.English); got != "📈 Lookups: 1 since 2025-05-01\nPrice: ¥200000 → ¥200000" {
+		t.Errorf("unexpected summary of one lookup %q", got)
+	}
+}
@@ -0,0 +1,25 @@
+package tgBot
+
+import (
+	"errors"
+	"mashinki/i18n"
+	"mashinki/logging"
+	"mashinki/parser"
+	"mashinki/render"
+)
+
+// riskSummary returns the condensed inspection report of the car, empty if the listing has none
+func (b *Bot) riskSummary(carID string, lang i18n.Lang) string {
+	if b.inspection == nil {
+		return ""
+	}
+
+	report, err := b.inspection(carID)
+	if err != nil {
+		if !errors.Is(err, parser.ErrNoReport) {
+			logging.DefaultLogger.LogErrorF("Error getting inspection report: %v", err)
+		}
+		return ""
+	}
+	return "\n\n" + render.RiskMarkdown(report.Summary(), lang)
+}
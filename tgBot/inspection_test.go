@@ -0,0 +1,31 @@
+package tgBot
+
+import (
+	"mashinki/i18n"
+	"mashinki/parser"
+	"strings"
+	"testing"
+)
+
+func TestRiskSummary(t *testing.T) {
+	b := &Bot{}
+	if text := b.riskSummary("1", i18n.Russian); text != "" {
+		t.Errorf("expected no summary without reports, got %q", text)
+	}
+
+	b.inspection = func(carID string) (parser.InspectionReport, error) {
+		if carID != "1" {
+			return parser.InspectionReport{}, parser.ErrNoReport
+		}
+		return parser.InspectionReport{Transfers: 1, Checks: []parser.InspectionCheck{
+			{Category: parser.RiskFire, Name: "防火墙"},
+		}}, nil
+	}
+
+	if text := b.riskSummary("1", i18n.Russian); !strings.HasPrefix(text, "\n\n🛡") || !strings.Contains(text, "пожар") {
+		t.Errorf("expected fire warning, got %q", text)
+	}
+	if text := b.riskSummary("2", i18n.Russian); text != "" {
+		t.Errorf("listing without report should have no summary, got %q", text)
+	}
+}
@@ -0,0 +1,62 @@
+package tgBot
+
+import (
+	"mashinki/i18n"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const callbackLanguage = "lang:"
+
+// lang returns the interface language of the chat
+func (b *Bot) lang(chatID int64) i18n.Lang {
+	if lang := b.getUserState(chatID).Language; lang != "" {
+		return lang
+	}
+	return i18n.Default
+}
+
+// detectLanguage sets the language of a new user from their Telegram settings
+func (b *Bot) detectLanguage(update tgbotapi.Update) {
+	chatID, ok := updateUserID(update)
+	if !ok {
+		return
+	}
+	from := update.SentFrom()
+	if from == nil {
+		return
+	}
+
+	state := b.getUserState(chatID)
+	if state.Language == "" {
+		state.Language = i18n.Parse(from.LanguageCode)
+		b.setUserState(chatID, state)
+	}
+}
+
+// languageKeyboard offers all supported languages
+func languageKeyboard() tgbotapi.InlineKeyboardMarkup {
+	var row []tgbotapi.InlineKeyboardButton
+	for _, lang := range i18n.Languages {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(lang.Name(), callbackLanguage+string(lang)))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(row)
+}
+
+// selectLanguage sets user's language or offers the list of languages
+func (b *Bot) selectLanguage(chatID int64, state *UserState, code string) tgbotapi.MessageConfig {
+	lang, ok := i18n.Lookup(code)
+	if !ok {
+		msg := tgbotapi.NewMessage(chatID, i18n.T(b.lang(chatID), "language.prompt"))
+		msg.ReplyMarkup = languageKeyboard()
+		return msg
+	}
+
+	state.Language = lang
+	b.setUserState(chatID, state)
+
+	// sending the keyboard again to update the button texts
+	msg := tgbotapi.NewMessage(chatID, i18n.T(state.Language, "language.selected", state.Language.Name()))
+	msg.ReplyMarkup = mainKeyboard(state.Language)
+	return msg
+}
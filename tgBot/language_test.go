@@ -0,0 +1,41 @@
+package tgBot
+
+import (
+	"mashinki/i18n"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestLanguageSelection(t *testing.T) {
+	b := &Bot{userStates: make(map[int64]*UserState)}
+
+	update := tgbotapi.Update{Message: &tgbotapi.Message{
+		From: &tgbotapi.User{ID: 42, LanguageCode: "en-GB"},
+		Chat: &tgbotapi.Chat{ID: 42},
+	}}
+	b.detectLanguage(update)
+	if lang := b.lang(42); lang != i18n.English {
+		t.Fatalf("expected english from Telegram settings, got %q", lang)
+	}
+
+	msg := b.selectLanguage(42, b.getUserState(42), "ky")
+	if b.lang(42) != i18n.Kyrgyz || msg.Text != i18n.T(i18n.Kyrgyz, "language.selected", i18n.Kyrgyz.Name()) {
+		t.Errorf("language was not changed: %q, %q", b.lang(42), msg.Text)
+	}
+
+	// chosen language is kept over Telegram settings
+	b.detectLanguage(update)
+	if lang := b.lang(42); lang != i18n.Kyrgyz {
+		t.Errorf("expected kyrgyz to be kept, got %q", lang)
+	}
+
+	msg = b.selectLanguage(42, b.getUserState(42), "de")
+	if _, ok := msg.ReplyMarkup.(tgbotapi.InlineKeyboardMarkup); !ok || b.lang(42) != i18n.Kyrgyz {
+		t.Errorf("unknown language should offer the list of languages")
+	}
+
+	if lang := b.lang(7); lang != i18n.Default {
+		t.Errorf("expected default language for unknown chat, got %q", lang)
+	}
+}
@@ -0,0 +1,77 @@
+package tgBot
+
+import (
+	"fmt"
+	"mashinki/i18n"
+	"mashinki/logging"
+	"mashinki/parser"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// layoutAlertInterval limits alerts about the same broken markup
+const layoutAlertInterval = time.Hour
+
+// layoutWatch saves pages with changed markup and tells admins about them
+type layoutWatch struct {
+	dir       string // папка для копий страниц
+	mu        sync.Mutex
+	lastAlert time.Time
+}
+
+// due tells whether admins should be alerted now, at most once per interval
+func (w *layoutWatch) due(now time.Time) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if now.Sub(w.lastAlert) < layoutAlertInterval {
+		return false
+	}
+	w.lastAlert = now
+	return true
+}
+
+// save writes a copy of the page and returns its path.
+// Every broken page is kept, the time with milliseconds keeps names apart.
+func (w *layoutWatch) save(err *parser.LayoutError, now time.Time) (string, error) {
+	if err := os.MkdirAll(w.dir, 0o755); err != nil {
+		return "", fmt.Errorf("error while creating %s: %v", w.dir, err)
+	}
+	path := filepath.Join(w.dir, now.Format("20060102-150405.000")+".html")
+	if err := os.WriteFile(path, []byte(err.Page), 0o644); err != nil {
+		return "", fmt.Errorf("error while saving page: %v", err)
+	}
+	return path, nil
+}
+
+// layoutChanged alerts admins that a lookup failed because che168 changed its markup
+func (b *Bot) layoutChanged(layoutErr *parser.LayoutError) {
+	if b.layout == nil {
+		return
+	}
+
+	now := time.Now()
+	path, err := b.layout.save(layoutErr, now)
+	if err != nil {
+		logging.DefaultLogger.LogErrorF("Error saving changed che168 page: %v", err)
+		path = "-"
+	}
+	if !b.layout.due(now) {
+		return
+	}
+
+	missing := make([]string, len(layoutErr.Missing))
+	for i, f := range layoutErr.Missing {
+		missing[i] = string(f)
+	}
+	for _, id := range b.access.adminIDs() {
+		text := i18n.T(b.lang(id), "admin.layout_alert", strings.Join(missing, ", "), layoutErr.URL, path)
+		if _, err := b.api.Send(tgbotapi.NewMessage(id, text)); err != nil {
+			logging.DefaultLogger.LogErrorF("Error sending layout alert to %d: %v", id, err)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package tgBot
+
+import (
+	"mashinki/parser"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLayoutWatch(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "layout")
+	b := &Bot{access: &access{admins: map[int64]bool{}}, layout: &layoutWatch{dir: dir}}
+
+	layoutErr := &parser.LayoutError{URL: "https://www.che168.com/1.html", Missing: []parser.Field{parser.FieldPrice}, Page: "<html></html>"}
+	b.layoutChanged(layoutErr)
+	time.Sleep(2 * time.Millisecond)
+	b.layoutChanged(layoutErr)
+	files, _ := os.ReadDir(dir)
+	if len(files) != 2 {
+		t.Fatalf("expected every broken page to be saved, got %d", len(files))
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, files[0].Name())); string(data) != "<html></html>" {
+		t.Errorf("unexpected page copy %q", data)
+	}
+
+	if b.layout.due(time.Now()) {
+		t.Errorf("admins should be alerted once per interval")
+	}
+	if !b.layout.due(time.Now().Add(layoutAlertInterval)) {
+		t.Errorf("admins should be alerted again after the interval")
+	}
+	(&Bot{}).layoutChanged(layoutErr)
+}
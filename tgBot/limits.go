@@ -0,0 +1,83 @@
+package tgBot
+
+import (
+	"context"
+	envhandler "mashinki/envHandler"
+	"mashinki/i18n"
+	"mashinki/ratelimit"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// limitsConfig reads limits from the environment, using defaults for unset values
+func limitsConfig() (ratelimit.Config, int) {
+	cfg := ratelimit.DefaultConfig()
+	cfg.Rate = float64(envhandler.GetEnvInt("RATE_LIMIT_PER_MINUTE", int(cfg.Rate*60))) / 60
+	cfg.Burst = envhandler.GetEnvInt("RATE_LIMIT_BURST", cfg.Burst)
+	cfg.MaxConcurrent = envhandler.GetEnvInt("MAX_USER_LOOKUPS", cfg.MaxConcurrent)
+	cfg.FloodMessages = envhandler.GetEnvInt("FLOOD_MESSAGES", cfg.FloodMessages)
+	cfg.FloodWindow = time.Duration(envhandler.GetEnvInt("FLOOD_WINDOW_SECONDS", int(cfg.FloodWindow.Seconds()))) * time.Second
+	cfg.BanDuration = time.Duration(envhandler.GetEnvInt("BAN_MINUTES", int(cfg.BanDuration.Minutes()))) * time.Minute
+
+	workers := envhandler.GetEnvInt("MAX_LOOKUP_WORKERS", 10)
+	return cfg, workers
+}
+
+// updateUserID returns the chat the update came from
+func updateUserID(update tgbotapi.Update) (int64, bool) {
+	switch {
+	case update.Message != nil:
+		return update.Message.Chat.ID, true
+	case update.CallbackQuery != nil && update.CallbackQuery.Message != nil:
+		return update.CallbackQuery.Message.Chat.ID, true
+	}
+	return 0, false
+}
+
+// checkLimits tells whether the update should be processed and warns limited users
+func (b *Bot) checkLimits(update tgbotapi.Update) bool {
+	chatID, ok := updateUserID(update)
+	if !ok {
+		return true
+	}
+
+	switch b.limiter.Allow(chatID) {
+	case ratelimit.Limited:
+		go b.sendText(chatID, i18n.T(b.lang(chatID), "limits.too_fast"))
+		return false
+	case ratelimit.JustBanned:
+		go b.sendText(chatID, i18n.T(b.lang(chatID), "limits.banned"))
+		return false
+	case ratelimit.Banned:
+		return false
+	}
+	return true
+}
+
+// acquireLookup reserves a lookup for the user, waiting in the queue if all workers are busy.
+// Returns false if the user has too many lookups in progress or the bot is stopping.
+func (b *Bot) acquireLookup(ctx context.Context, chatID int64) bool {
+	if !b.limiter.Acquire(chatID) {
+		b.sendText(chatID, i18n.T(b.lang(chatID), "limits.wait_previous"))
+		return false
+	}
+	b.trackLookup(chatID, 1)
+
+	err := b.queue.Wait(ctx, func(position int) {
+		b.sendText(chatID, i18n.T(b.lang(chatID), "limits.queue", position))
+	})
+	if err != nil {
+		b.trackLookup(chatID, -1)
+		b.limiter.Release(chatID)
+		return false
+	}
+	return true
+}
+
+// releaseLookup frees the lookup reserved by acquireLookup
+func (b *Bot) releaseLookup(chatID int64) {
+	b.queue.Done()
+	b.trackLookup(chatID, -1)
+	b.limiter.Release(chatID)
+}
@@ -0,0 +1,65 @@
+package tgBot
+
+import (
+	"context"
+	"errors"
+	"mashinki/i18n"
+	"mashinki/logging"
+	"mashinki/market"
+	"mashinki/render"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const callbackMarket = "market:"
+
+// sendMarket compares the last calculated car with other listings of its spec
+func (b *Bot) sendMarket(ctx context.Context, chatID int64, carID string) {
+	if !b.acquireLookup(ctx, chatID) {
+		return
+	}
+	defer b.releaseLookup(chatID)
+
+	msg := b.marketAnalysis(ctx, chatID, carID)
+	if ctx.Err() != nil {
+		return
+	}
+	if _, err := b.api.Send(msg); err != nil {
+		logging.DefaultLogger.LogErrorF("Error sending message: %v", err)
+	}
+}
+
+func (b *Bot) marketAnalysis(ctx context.Context, chatID int64, carID string) tgbotapi.MessageConfig {
+	lang := b.lang(chatID)
+	state := b.getUserState(chatID)
+
+	reply := func(key string) tgbotapi.MessageConfig {
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, key))
+		msg.ReplyMarkup = mainKeyboard(lang)
+		return msg
+	}
+
+	if state.LastResult == nil || state.LastResult.Car.CarId != carID {
+		return reply("quote.outdated")
+	}
+
+	analysis, err := b.market.Analyze(ctx, state.LastResult.Car)
+	switch {
+	case errors.Is(err, market.ErrNotEnoughData), errors.Is(err, market.ErrNoSpec):
+		return reply("market.not_enough")
+	case err != nil:
+		logging.DefaultLogger.LogErrorF("Error analyzing market: %v", err)
+		return reply("market.error")
+	}
+
+	msg := tgbotapi.NewMessage(chatID, render.MarketMarkdown(analysis, lang))
+	msg.ParseMode = "Markdown"
+	return msg
+}
+
+// marketButton is shown under results of cars with a known spec
+func marketButton(carID string, lang i18n.Lang) []tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "btn.market"), callbackMarket+carID),
+	)
+}
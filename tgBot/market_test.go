@@ -0,0 +1,52 @@
+package tgBot
+
+import (
+	"context"
+	"mashinki/i18n"
+	"mashinki/market"
+	"mashinki/parser"
+	"mashinki/storage"
+	"mashinki/taxes"
+	"strings"
+	"testing"
+)
+
+func TestMarketAnalysis(t *testing.T) {
+	store, err := market.NewStore(storage.Open(t.TempDir(), "market.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	analyzer := market.NewAnalyzer(store)
+	analyzer.Listings = func(q parser.SearchQuery, page int) ([]parser.Listing, error) {
+		if page > 1 || q.SpecID != "42" {
+			return nil, nil
+		}
+		var listings []parser.Listing
+		for i, price := range []float64{100_000, 110_000, 120_000, 130_000, 140_000} {
+			listings = append(listings, parser.Listing{CarId: string(rune('a' + i)), Year: 2021, Price: price})
+		}
+		return listings, nil
+	}
+	b := &Bot{market: analyzer, userStates: make(map[int64]*UserState)}
+
+	if msg := b.marketAnalysis(context.Background(), 1, "5"); msg.Text != i18n.T(i18n.Russian, "quote.outdated") {
+		t.Errorf("expected outdated reply, got %q", msg.Text)
+	}
+
+	result := taxes.Result{Car: parser.CarInfo{CarId: "5", SpecID: "42", Year: "2021-01", Price: 150_000}}
+	b.setUserState(1, &UserState{LastResult: &result})
+	if msg := b.marketAnalysis(context.Background(), 1, "5"); !strings.Contains(msg.Text, "Дороже рынка") {
+		t.Errorf("expected expensive verdict, got %q", msg.Text)
+	}
+
+	result.Car.SpecID = "7"
+	if msg := b.marketAnalysis(context.Background(), 1, "5"); msg.Text != i18n.T(i18n.Russian, "market.not_enough") {
+		t.Errorf("expected not enough data, got %q", msg.Text)
+	}
+
+	car := completeCar("5")
+	car.SpecID = "42"
+	if keyboard := quoteKeyboard(car, i18n.Russian); len(keyboard.InlineKeyboard) != 3 {
+		t.Errorf("cars with a spec should have the market button")
+	}
+}
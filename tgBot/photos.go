@@ -0,0 +1,176 @@
+package tgBot
+
+import (
+	"context"
+	"fmt"
+	"mashinki/logging"
+	"mashinki/parser"
+	"mashinki/storage"
+	"sync"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	maxMediaPhotos  = 10 // больше Telegram не принимает в одной группе
+	photoWorkers    = 4
+	maxCachedPhotos = 10_000
+)
+
+// photoCache keeps Telegram file ids of uploaded photos,
+// so photos of a car are uploaded only on the first lookup
+type photoCache struct {
+	file *storage.File
+	mu   sync.Mutex
+	data photoCacheData
+}
+
+type photoCacheData struct {
+	FileIDs map[string]string `json:"file_ids"` // адрес фото -> file_id
+	Order   []string          `json:"order"`    // в порядке добавления, старые удаляются первыми
+}
+
+func newPhotoCache(file *storage.File) (*photoCache, error) {
+	c := &photoCache{file: file, data: photoCacheData{FileIDs: make(map[string]string)}}
+	if err := file.Load(&c.data); err != nil {
+		return nil, fmt.Errorf("error while loading photo cache: %v", err)
+	}
+	if c.data.FileIDs == nil {
+		c.data.FileIDs = make(map[string]string)
+	}
+	return c, nil
+}
+
+func (c *photoCache) get(url string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fileID, ok := c.data.FileIDs[url]
+	return fileID, ok
+}
+
+// put remembers file ids of uploaded photos
+func (c *photoCache) put(fileIDs map[string]string) error {
+	if len(fileIDs) == 0 {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for url, fileID := range fileIDs {
+		if _, exists := c.data.FileIDs[url]; !exists {
+			c.data.Order = append(c.data.Order, url)
+		}
+		c.data.FileIDs[url] = fileID
+	}
+	for len(c.data.Order) > maxCachedPhotos {
+		delete(c.data.FileIDs, c.data.Order[0])
+		c.data.Order = c.data.Order[1:]
+	}
+	return c.file.Save(c.data)
+}
+
+// photoMedia prepares up to maxMediaPhotos photos of the car: cached ones are sent by file id,
+// others are downloaded through the proxy. Returns addresses of photos in the same order.
+func (b *Bot) photoMedia(ctx context.Context, car parser.CarInfo) ([]interface{}, []string) {
+	var urls []string
+	for _, photo := range car.Photos {
+		if len(urls) == maxMediaPhotos {
+			break
+		}
+		urls = append(urls, parser.PhotoURL(photo, b.photoSize))
+	}
+
+	files := make([]tgbotapi.RequestFileData, len(urls))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, photoWorkers)
+	for i, url := range urls {
+		if fileID, ok := b.photos.get(url); ok {
+			files[i] = tgbotapi.FileID(fileID)
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, url string) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
+
+			data, err := b.downloadPhoto(url)
+			if err != nil {
+				logging.DefaultLogger.LogErrorF("Error downloading photo %s: %v", url, err)
+				return
+			}
+			files[i] = tgbotapi.FileBytes{Name: fmt.Sprintf("%s_%d.jpg", car.CarId, i+1), Bytes: data}
+		}(i, url)
+	}
+	wg.Wait()
+
+	// skipping photos that failed to download
+	var media []interface{}
+	var sent []string
+	for i, file := range files {
+		if file == nil {
+			continue
+		}
+		photo := tgbotapi.NewInputMediaPhoto(file)
+		if len(media) == 0 {
+			photo.Caption = car.FullName
+		}
+		media = append(media, photo)
+		sent = append(sent, urls[i])
+	}
+	return media, sent
+}
+
+// sendMedia sends one photo as a regular photo message, Telegram does not accept media groups of one item
+func (b *Bot) sendMedia(chatID int64, media []interface{}) ([]tgbotapi.Message, error) {
+	if len(media) > 1 {
+		return b.api.SendMediaGroup(tgbotapi.NewMediaGroup(chatID, media))
+	}
+
+	item := media[0].(tgbotapi.InputMediaPhoto)
+	photo := tgbotapi.NewPhoto(chatID, item.Media)
+	photo.Caption = item.Caption
+	m, err := b.api.Send(photo)
+	if err != nil {
+		return nil, err
+	}
+	return []tgbotapi.Message{m}, nil
+}
+
+// sendPhotos sends photos of the car as a media group and caches file ids of uploaded ones
+func (b *Bot) sendPhotos(ctx context.Context, chatID int64, car parser.CarInfo) {
+	if b.photos == nil || len(car.Photos) == 0 {
+		return
+	}
+
+	media, urls := b.photoMedia(ctx, car)
+	if len(media) == 0 {
+		return
+	}
+
+	messages, err := b.sendMedia(chatID, media)
+	if err != nil {
+		logging.DefaultLogger.LogErrorF("Error sending photos: %v", err)
+		return
+	}
+
+	uploaded := make(map[string]string)
+	for i, m := range messages {
+		if i >= len(urls) || len(m.Photo) == 0 {
+			continue
+		}
+		if _, cached := b.photos.get(urls[i]); !cached {
+			// the last size is the largest one
+			uploaded[urls[i]] = m.Photo[len(m.Photo)-1].FileID
+		}
+	}
+	if err := b.photos.put(uploaded); err != nil {
+		logging.DefaultLogger.LogErrorF("Error saving photo cache: %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+package tgBot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mashinki/parser"
+	"mashinki/storage"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestPhotoMedia(t *testing.T) {
+	file := storage.Open(t.TempDir(), "photos.json")
+	cache, err := newPhotoCache(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var car parser.CarInfo
+	car.FullName = "Test car"
+	for i := 0; i < 12; i++ {
+		car.Photos = append(car.Photos, fmt.Sprintf("https://2sc2.autoimg.cn/escimg/autohomecar__photo%d.jpg", i))
+	}
+
+	var downloaded []string
+	b := &Bot{
+		photos:    cache,
+		photoSize: parser.PhotoMedium,
+		downloadPhoto: func(url string) ([]byte, error) {
+			if strings.Contains(url, "photo3.") {
+				return nil, errors.New("not found")
+			}
+			downloaded = append(downloaded, url)
+			return []byte("jpeg"), nil
+		},
+	}
+
+	first := parser.PhotoURL(car.Photos[0], parser.PhotoMedium)
+	if err := cache.put(map[string]string{first: "cached-id"}); err != nil {
+		t.Fatal(err)
+	}
+
+	media, urls := b.photoMedia(context.Background(), car)
+	if len(media) != maxMediaPhotos-1 || len(urls) != len(media) {
+		t.Fatalf("expected %d photos without the broken one, got %d", maxMediaPhotos-1, len(media))
+	}
+	if len(downloaded) != maxMediaPhotos-2 {
+		t.Errorf("cached photo should not be downloaded, downloaded %d", len(downloaded))
+	}
+
+	photo := media[0].(tgbotapi.InputMediaPhoto)
+	if photo.Media != tgbotapi.FileID("cached-id") || photo.Caption != "Test car" {
+		t.Errorf("first photo should be sent by file id with caption, got %+v", photo)
+	}
+	if urls[1] != parser.PhotoURL(car.Photos[1], parser.PhotoMedium) {
+		t.Errorf("photos should be requested in the chosen size, got %q", urls[1])
+	}
+
+	// file ids are kept after restart
+	cache, err = newPhotoCache(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id, ok := cache.get(first); !ok || id != "cached-id" {
+		t.Errorf("file id is not saved")
+	}
+}
+
+func TestSendSinglePhoto(t *testing.T) {
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:])
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/getMe"):
+			fmt.Fprint(w, `{"ok": true, "result": {"id": 1, "is_bot": true, "first_name": "bot", "username": "bot"}}`)
+		case strings.HasSuffix(r.URL.Path, "/sendPhoto"):
+			fmt.Fprint(w, `{"ok": true, "result": {"message_id": 1, "chat": {"id": 42}, "date": 0,
+				"photo": [{"file_id": "small"}, {"file_id": "large"}]}}`)
+		default:
+			fmt.Fprint(w, `{"ok": false, "description": "unexpected method"}`)
+		}
+	}))
+	defer server.Close()
+
+	api, err := tgbotapi.NewBotAPIWithAPIEndpoint("token", server.URL+"/bot%s/%s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache, err := newPhotoCache(storage.Open(t.TempDir(), "photos.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var car parser.CarInfo
+	car.Photos = []string{"https://2sc2.autoimg.cn/escimg/autohomecar__photo1.jpg"}
+	b := &Bot{
+		api:           api,
+		photos:        cache,
+		photoSize:     parser.PhotoMedium,
+		downloadPhoto: func(string) ([]byte, error) { return []byte("jpeg"), nil },
+	}
+	b.sendPhotos(context.Background(), 42, car)
+
+	if len(methods) != 2 || methods[1] != "sendPhoto" {
+		t.Fatalf("one photo should be sent as a photo message, got %v", methods)
+	}
+	if id, ok := cache.get(parser.PhotoURL(car.Photos[0], parser.PhotoMedium)); !ok || id != "large" {
+		t.Errorf("file id of the largest size should be cached, got %q", id)
+	}
+}
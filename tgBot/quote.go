@@ -0,0 +1,139 @@
+package tgBot
+
+import (
+	"context"
+	"mashinki/i18n"
+	"mashinki/logging"
+	"mashinki/parser"
+	"mashinki/quote"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	btnQuotePDF = "btn.quote_pdf"
+	btnQuotePNG = "btn.quote_png"
+
+	callbackQuotePDF = "quote_pdf:"
+	callbackQuotePNG = "quote_png:"
+)
+
+// quoteKeyboard is attached to the result to get a quote for the car.
+// Cars with a known spec can be compared with the market, cars with incomplete data can be filled in,
+// listings of dealers can be reported as not matching the car.
+func quoteKeyboard(car parser.CarInfo, lang i18n.Lang) tgbotapi.InlineKeyboardMarkup {
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, btnQuotePDF), callbackQuotePDF+car.CarId),
+			tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, btnQuotePNG), callbackQuotePNG+car.CarId),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "country.compare"), callbackCompareCountries+car.CarId),
+		),
+	)
+	if car.SpecID != "" {
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, marketButton(car.CarId, lang))
+	}
+	if len(car.Validate()) > 0 {
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, fillButton(car.CarId, lang))
+	}
+	if car.Seller.DealerID != "" {
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, reportButton(car.CarId, lang))
+	}
+	return keyboard
+}
+
+func (b *Bot) handleCallback(ctx context.Context, query *tgbotapi.CallbackQuery) {
+	if _, err := b.api.Request(tgbotapi.NewCallback(query.ID, "")); err != nil {
+		logging.DefaultLogger.LogErrorF("Error answering callback: %v", err)
+	}
+	if query.Message == nil {
+		return
+	}
+	chatID := query.Message.Chat.ID
+
+	switch {
+	case strings.HasPrefix(query.Data, callbackLanguage):
+		msg := b.selectLanguage(chatID, b.getUserState(chatID), strings.TrimPrefix(query.Data, callbackLanguage))
+		if _, err := b.api.Send(msg); err != nil {
+			logging.DefaultLogger.LogErrorF("Error sending message: %v", err)
+		}
+	case strings.HasPrefix(query.Data, callbackCompareCountries):
+		msg := b.compareCountries(chatID, strings.TrimPrefix(query.Data, callbackCompareCountries))
+		if _, err := b.api.Send(msg); err != nil {
+			logging.DefaultLogger.LogErrorF("Error sending message: %v", err)
+		}
+	case strings.HasPrefix(query.Data, callbackCountry):
+		msg := b.selectCountry(chatID, b.getUserState(chatID), strings.TrimPrefix(query.Data, callbackCountry))
+		if _, err := b.api.Send(msg); err != nil {
+			logging.DefaultLogger.LogErrorF("Error sending message: %v", err)
+		}
+	case strings.HasPrefix(query.Data, callbackSubPause),
+		strings.HasPrefix(query.Data, callbackSubResume),
+		strings.HasPrefix(query.Data, callbackSubDelete):
+		b.manageSubscription(chatID, query.Message.MessageID, query.Data)
+	case strings.HasPrefix(query.Data, callbackSearchPage):
+		b.turnSearchPage(chatID, query.Message.MessageID, strings.TrimPrefix(query.Data, callbackSearchPage))
+	case strings.HasPrefix(query.Data, callbackSearchCar):
+		msg := b.showSearchCar(chatID, strings.TrimPrefix(query.Data, callbackSearchCar))
+		if _, err := b.api.Send(msg); err != nil {
+			logging.DefaultLogger.LogErrorF("Error sending message: %v", err)
+		}
+	case strings.HasPrefix(query.Data, callbackMarket):
+		b.sendMarket(ctx, chatID, strings.TrimPrefix(query.Data, callbackMarket))
+	case strings.HasPrefix(query.Data, callbackFill):
+		b.sendText(chatID, b.fillHelp(chatID, strings.TrimPrefix(query.Data, callbackFill)))
+	case strings.HasPrefix(query.Data, callbackReport):
+		msg := b.reportMismatch(chatID, strings.TrimPrefix(query.Data, callbackReport))
+		if _, err := b.api.Send(msg); err != nil {
+			logging.DefaultLogger.LogErrorF("Error sending message: %v", err)
+		}
+	case strings.HasPrefix(query.Data, callbackQuotePDF):
+		b.sendQuote(chatID, strings.TrimPrefix(query.Data, callbackQuotePDF), true)
+	case strings.HasPrefix(query.Data, callbackQuotePNG):
+		b.sendQuote(chatID, strings.TrimPrefix(query.Data, callbackQuotePNG), false)
+	}
+}
+
+// sendQuote sends the quote for the last calculated car as a document
+func (b *Bot) sendQuote(chatID int64, carID string, asPDF bool) {
+	state := b.getUserState(chatID)
+	if state.LastResult == nil || state.LastResult.Car.CarId != carID {
+		b.sendText(chatID, i18n.T(b.lang(chatID), "quote.outdated"))
+		return
+	}
+
+	q := quote.New(*state.LastResult, b.brand, b.lang(chatID))
+
+	var (
+		data []byte
+		name string
+		err  error
+	)
+	if asPDF {
+		data, err = q.PDF()
+		name = "kp_" + carID + ".pdf"
+	} else {
+		data, err = q.PNG()
+		name = "kp_" + carID + ".png"
+	}
+	if err != nil {
+		logging.DefaultLogger.LogErrorF("Error generating quote: %v", err)
+		b.sendText(chatID, i18n.T(b.lang(chatID), "quote.error"))
+		return
+	}
+
+	doc := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{Name: name, Bytes: data})
+	if _, err := b.api.Send(doc); err != nil {
+		logging.DefaultLogger.LogErrorF("Error sending quote: %v", err)
+	}
+}
+
+func (b *Bot) sendText(chatID int64, text string) {
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = mainKeyboard(b.lang(chatID))
+	if _, err := b.api.Send(msg); err != nil {
+		logging.DefaultLogger.LogErrorF("Error sending message: %v", err)
+	}
+}
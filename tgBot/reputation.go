@@ -0,0 +1,83 @@
+package tgBot
+
+import (
+	"mashinki/i18n"
+	"mashinki/logging"
+	"mashinki/parser"
+	"mashinki/reputation"
+	"mashinki/search"
+	"math"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	callbackReport = "dealer_report:"
+
+	// цена в поиске и в объявлении может отличаться из-за округления до тысяч юаней
+	priceMismatch = 0.05
+)
+
+// recordDealer saves the outcome of the dealer's listing, private sellers are skipped
+func (b *Bot) recordDealer(seller parser.Seller, carID string, outcome reputation.Outcome) {
+	if b.dealers == nil {
+		return
+	}
+	if err := b.dealers.Record(seller.DealerID, seller.Name, carID, outcome); err != nil {
+		logging.DefaultLogger.LogErrorF("Error saving dealer reputation: %v", err)
+	}
+}
+
+// recordRemoved remembers that the listing is no longer on che168
+func (b *Bot) recordRemoved(url string) {
+	b.recordDealer(parser.Seller{DealerID: parser.DealerIDFromURL(url)}, parser.CarIDFromURL(url), reputation.OutcomeStale)
+}
+
+// recordSearch checks found cars: the price in the search must match the price of the listing
+func (b *Bot) recordSearch(cars []search.Car) {
+	for _, car := range cars {
+		outcome := reputation.OutcomeOK
+		if listed, actual := car.Listing.Price, car.Result.Car.Price; listed > 0 && actual > 0 &&
+			math.Abs(listed-actual)/actual > priceMismatch {
+			outcome = reputation.OutcomeMismatch
+		}
+		b.recordDealer(car.Result.Car.Seller, car.Listing.CarId, outcome)
+	}
+}
+
+// dealerWarning warns about a dealer with many removed or mismatched listings, empty otherwise
+func (b *Bot) dealerWarning(seller parser.Seller, lang i18n.Lang) string {
+	if b.dealers == nil || seller.DealerID == "" {
+		return ""
+	}
+	dealer, ok := b.dealers.Get(seller.DealerID)
+	if !ok || !dealer.Unreliable() {
+		return ""
+	}
+	checked, stale, mismatched := dealer.Stats()
+	return "\n\n⚠️ *" + i18n.T(lang, "reputation.warning") + "*\n" + i18n.T(lang, "reputation.stats", checked, stale, mismatched)
+}
+
+// reportMismatch is a user report that the car does not match its listing
+func (b *Bot) reportMismatch(chatID int64, carID string) tgbotapi.MessageConfig {
+	lang := b.lang(chatID)
+	state := b.getUserState(chatID)
+
+	key := "reputation.reported"
+	switch {
+	case state.LastResult == nil || state.LastResult.Car.CarId != carID:
+		key = "quote.outdated"
+	case state.LastResult.Car.Seller.DealerID == "":
+		key = "reputation.private"
+	default:
+		b.recordDealer(state.LastResult.Car.Seller, carID, reputation.OutcomeMismatch)
+	}
+	return tgbotapi.NewMessage(chatID, i18n.T(lang, key))
+}
+
+// reportButton is shown under results of dealer listings
+func reportButton(carID string, lang i18n.Lang) []tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "btn.report"), callbackReport+carID),
+	)
+}
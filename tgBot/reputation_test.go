@@ -0,0 +1,75 @@
+package tgBot
+
+import (
+	"mashinki/i18n"
+	"mashinki/parser"
+	"mashinki/reputation"
+	"mashinki/search"
+	"mashinki/storage"
+	"mashinki/taxes"
+	"strings"
+	"testing"
+)
+
+func TestDealerReputation(t *testing.T) {
+	dealers, err := reputation.NewStore(storage.Open(t.TempDir(), "dealers.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &Bot{dealers: dealers}
+	seller := parser.Seller{Type: parser.SellerDealer, DealerID: "10", Name: "车行"}
+
+	car := func(id string, listed, actual float64) search.Car {
+		return search.Car{
+			Listing: parser.Listing{CarId: id, Price: listed},
+			Result:  taxes.Result{Car: parser.CarInfo{CarId: id, Price: actual, Seller: seller}},
+		}
+	}
+	// 150 000 in the search, but 180 000 in the listing
+	b.recordSearch([]search.Car{car("1", 150_000, 151_000), car("2", 150_000, 180_000), car("3", 90_000, 90_000)})
+	if text := b.dealerWarning(seller, i18n.Russian); !strings.Contains(text, "⚠️") || !strings.Contains(text, "3") {
+		t.Errorf("expected warning about the dealer, got %q", text)
+	}
+
+	if text := b.dealerWarning(parser.Seller{Type: parser.SellerPrivate}, i18n.Russian); text != "" {
+		t.Errorf("private sellers have no reputation, got %q", text)
+	}
+	if text := (&Bot{}).dealerWarning(seller, i18n.Russian); text != "" {
+		t.Errorf("expected no warning without the store, got %q", text)
+	}
+
+	b.recordRemoved("https://www.che168.com/dealer/20/99.html")
+	if d, ok := dealers.Get("20"); !ok || d.Cars["99"] != reputation.OutcomeStale {
+		t.Errorf("removed listing is not recorded: %+v", d)
+	}
+
+	dealerCar := completeCar("1")
+	dealerCar.Seller = seller
+	if keyboard := quoteKeyboard(dealerCar, i18n.Russian); len(keyboard.InlineKeyboard) != 3 {
+		t.Errorf("dealer listings should have a report button")
+	}
+	if keyboard := quoteKeyboard(completeCar("1"), i18n.Russian); len(keyboard.InlineKeyboard) != 2 {
+		t.Errorf("private listings should have no report button")
+	}
+}
+
+func TestReportMismatch(t *testing.T) {
+	dealers, err := reputation.NewStore(storage.Open(t.TempDir(), "dealers.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &Bot{dealers: dealers, userStates: make(map[int64]*UserState)}
+
+	if msg := b.reportMismatch(1, "5"); msg.Text != i18n.T(i18n.Russian, "quote.outdated") {
+		t.Errorf("expected outdated report, got %q", msg.Text)
+	}
+
+	result := taxes.Result{Car: parser.CarInfo{CarId: "5", Seller: parser.Seller{Type: parser.SellerDealer, DealerID: "10"}}}
+	b.setUserState(1, &UserState{LastResult: &result})
+	if msg := b.reportMismatch(1, "5"); msg.Text != i18n.T(i18n.Russian, "reputation.reported") {
+		t.Errorf("unexpected reply %q", msg.Text)
+	}
+	if d, _ := dealers.Get("10"); d.Cars["5"] != reputation.OutcomeMismatch {
+		t.Errorf("report is not recorded: %+v", d)
+	}
+}
@@ -0,0 +1,139 @@
+package tgBot
+
+import (
+	"context"
+	"fmt"
+	"mashinki/i18n"
+	"mashinki/logging"
+	"mashinki/render"
+	"mashinki/search"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	cmdSearch = "search"
+
+	callbackSearchPage = "search_page:"
+	callbackSearchCar  = "search_car:"
+
+	searchPageSize = 5
+)
+
+// searchCars answers /search with the cheapest matching listings
+func (b *Bot) searchCars(ctx context.Context, chatID int64, state *UserState, args string) tgbotapi.MessageConfig {
+	lang := b.lang(chatID)
+
+	reply := func(key string) tgbotapi.MessageConfig {
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, key))
+		msg.ReplyMarkup = mainKeyboard(lang)
+		return msg
+	}
+
+	criteria, err := search.ParseCriteria(args)
+	if err != nil {
+		return reply("search.usage")
+	}
+
+	if _, err := b.api.Send(tgbotapi.NewMessage(chatID, i18n.T(lang, "search.processing"))); err != nil {
+		logging.DefaultLogger.LogErrorF("Error sending processing message: %v", err)
+	}
+
+	cars, err := search.New(lang.TranslationTarget()).Search(ctx, criteria, search.DefaultLimit, state.CostProfile)
+	b.stats.record(chatID, err)
+	switch {
+	case err != nil:
+		logging.DefaultLogger.LogErrorF("Error searching cars: %v", err)
+		return reply("search.error")
+	case len(cars) == 0:
+		return reply("search.not_found")
+	}
+
+	b.recordSearch(cars)
+	for _, car := range cars {
+		b.recordHistory(car.Result.Car)
+	}
+	state.SearchResults = cars
+	b.setUserState(chatID, state)
+
+	text, keyboard := searchPage(cars, 0, lang)
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "Markdown"
+	msg.ReplyMarkup = keyboard
+	return msg
+}
+
+// searchPage renders one page of found cars with buttons to open them and to turn pages
+func searchPage(cars []search.Car, page int, lang i18n.Lang) (string, tgbotapi.InlineKeyboardMarkup) {
+	pages := (len(cars) + searchPageSize - 1) / searchPageSize
+	page = max(0, min(page, pages-1))
+	from, to := page*searchPageSize, min((page+1)*searchPageSize, len(cars))
+
+	var sb strings.Builder
+	sb.WriteString("🔎 " + i18n.T(lang, "search.found", len(cars), page+1, pages) + "\n")
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i := from; i < to; i++ {
+		r := cars[i].Result
+		fmt.Fprintf(&sb, "\n*%d. %s*\n📅 %s  📊 %s\n💰 ¥%.0f → 💵 %.2f ₽\n",
+			i+1, render.EscapeMarkdown(r.Car.FullName), r.Car.Year, r.Car.Mileage.Format(i18n.T(lang, "unit.km")), r.Car.Price, r.Total)
+
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(
+			fmt.Sprintf("%d. %s", i+1, r.Car.FullName), callbackSearchCar+strconv.Itoa(i))))
+	}
+
+	var nav []tgbotapi.InlineKeyboardButton
+	if page > 0 {
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData("◀️", callbackSearchPage+strconv.Itoa(page-1)))
+	}
+	if page < pages-1 {
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData("▶️", callbackSearchPage+strconv.Itoa(page+1)))
+	}
+	if len(nav) > 0 {
+		rows = append(rows, nav)
+	}
+
+	return sb.String(), tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// turnSearchPage edits the list of found cars to show another page
+func (b *Bot) turnSearchPage(chatID int64, messageID int, data string) {
+	state := b.getUserState(chatID)
+	page, err := strconv.Atoi(data)
+	if err != nil || len(state.SearchResults) == 0 {
+		b.sendText(chatID, i18n.T(b.lang(chatID), "search.outdated"))
+		return
+	}
+
+	text, keyboard := searchPage(state.SearchResults, page, b.lang(chatID))
+	edit := tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID, text, keyboard)
+	edit.ParseMode = "Markdown"
+	if _, err := b.api.Send(edit); err != nil {
+		logging.DefaultLogger.LogErrorF("Error editing search page: %v", err)
+	}
+}
+
+// showSearchCar sends the full calculation of a found car
+func (b *Bot) showSearchCar(chatID int64, data string) tgbotapi.MessageConfig {
+	lang := b.lang(chatID)
+	state := b.getUserState(chatID)
+
+	i, err := strconv.Atoi(data)
+	if err != nil || i < 0 || i >= len(state.SearchResults) {
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "search.outdated"))
+		msg.ReplyMarkup = mainKeyboard(lang)
+		return msg
+	}
+
+	car := state.SearchResults[i]
+	state.LastResult = &car.Result
+	b.setUserState(chatID, state)
+
+	msg := tgbotapi.NewMessage(chatID, render.Markdown(car.Result, lang)+b.riskSummary(car.Listing.CarId, lang)+
+		b.dealerWarning(car.Result.Car.Seller, lang)+issuesText(car.Result.Car, lang)+"\n🔗 "+car.Listing.URL)
+	msg.ParseMode = "Markdown"
+	msg.ReplyMarkup = quoteKeyboard(car.Result.Car, lang)
+	return msg
+}
@@ -0,0 +1,50 @@
+package tgBot
+
+import (
+	"fmt"
+	"mashinki/i18n"
+	"mashinki/parser"
+	"mashinki/search"
+	"mashinki/taxes"
+	"strings"
+	"testing"
+)
+
+func TestSearchPage(t *testing.T) {
+	var cars []search.Car
+	for i := 0; i < 12; i++ {
+		id := fmt.Sprint(i)
+		cars = append(cars, search.Car{
+			Listing: parser.Listing{CarId: id, Name: "车 " + id},
+			Result:  taxes.Result{Car: parser.CarInfo{CarId: id, FullName: "Car_" + id}, Total: float64(i)},
+		})
+	}
+
+	text, keyboard := searchPage(cars, 0, i18n.Russian)
+	if !strings.Contains(text, i18n.T(i18n.Russian, "search.found", 12, 1, 3)) {
+		t.Errorf("unexpected header:\n%s", text)
+	}
+	rows := keyboard.InlineKeyboard
+	if len(rows) != searchPageSize+1 || len(rows[searchPageSize]) != 1 || *rows[searchPageSize][0].CallbackData != callbackSearchPage+"1" {
+		t.Errorf("first page should have cars and the next button, got %+v", rows)
+	}
+
+	text, keyboard = searchPage(cars, 5, i18n.Russian)
+	rows = keyboard.InlineKeyboard
+	if !strings.Contains(text, "*11. Car\\_10*") || rows[0][0].Text != "11. Car_10" || len(rows) != 3 || *rows[2][0].CallbackData != callbackSearchPage+"1" {
+		t.Errorf("page out of range should show the last page, got\n%s", text)
+	}
+
+	b := &Bot{userStates: make(map[int64]*UserState)}
+	state := b.getUserState(42)
+	state.SearchResults = cars
+	b.setUserState(42, state)
+
+	msg := b.showSearchCar(42, "3")
+	if b.getUserState(42).LastResult == nil || b.getUserState(42).LastResult.Car.CarId != "3" {
+		t.Errorf("found car should become the last result for the quote")
+	}
+	if msg = b.showSearchCar(42, "12"); msg.Text != i18n.T(i18n.Default, "search.outdated") {
+		t.Errorf("expected outdated results, got %q", msg.Text)
+	}
+}
@@ -0,0 +1,101 @@
+package tgBot
+
+import (
+	"log"
+	"mashinki/i18n"
+	"mashinki/logging"
+	"mashinki/parser"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// cancelGracePeriod is how long cancelled handlers have to finish
+const cancelGracePeriod = 5 * time.Second
+
+// trackLookup counts lookups in progress to notify users if they are cancelled
+func (b *Bot) trackLookup(chatID int64, delta int) {
+	b.inFlightMu.Lock()
+	defer b.inFlightMu.Unlock()
+
+	b.inFlight[chatID] += delta
+	if b.inFlight[chatID] <= 0 {
+		delete(b.inFlight, chatID)
+	}
+}
+
+// Stop stops accepting updates and waits for in-flight lookups.
+// Lookups not finished within the shutdown timeout are cancelled and their users are notified.
+func (b *Bot) Stop() {
+	// Stopping receiving updates, webhook updates already accepted are still dispatched
+	if b.webhookServer != nil {
+		b.stopWebhook()
+	} else {
+		b.api.StopReceivingUpdates()
+	}
+	if b.cancel != nil {
+		b.cancel()
+	}
+	parser.OnLayoutChange(nil)
+
+	// no handlers are started after run returns
+	if b.runDone != nil {
+		<-b.runDone
+	}
+
+	if !b.waitHandlers(b.shutdownTimeout) {
+		b.inFlightMu.Lock()
+		chats := make([]int64, 0, len(b.inFlight))
+		for chatID := range b.inFlight {
+			chats = append(chats, chatID)
+		}
+		b.inFlightMu.Unlock()
+
+		log.Printf("Cancelling %d unfinished lookups", len(chats))
+		b.cancelWork()
+
+		for _, chatID := range chats {
+			msg := tgbotapi.NewMessage(chatID, i18n.T(b.lang(chatID), "shutdown.cancelled"))
+			if _, err := b.api.Send(msg); err != nil {
+				logging.DefaultLogger.LogErrorF("Error notifying %d about shutdown: %v", chatID, err)
+			}
+		}
+
+		b.waitHandlers(cancelGracePeriod)
+	}
+	b.cancelWork()
+
+	b.acknowledgeUpdates()
+}
+
+// waitHandlers waits for all handlers, returns false on timeout
+func (b *Bot) waitHandlers(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		b.handlers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// acknowledgeUpdates confirms all dispatched updates so Telegram doesn't send them again after restart.
+// Webhook updates are confirmed by the HTTP response.
+func (b *Bot) acknowledgeUpdates() {
+	lastUpdateID := b.lastUpdateID.Load()
+	if b.webhookServer != nil || lastUpdateID == 0 {
+		return
+	}
+
+	u := tgbotapi.NewUpdate(int(lastUpdateID) + 1)
+	u.Limit = 1
+	u.Timeout = 0
+	if _, err := b.api.GetUpdates(u); err != nil {
+		logging.DefaultLogger.LogErrorF("Error acknowledging updates: %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package tgBot
+
+import (
+	"mashinki/i18n"
+	"strings"
+	"sync"
+	"time"
+)
+
+// dayStats are lookup counters of one day
+type dayStats struct {
+	lookups int
+	errors  int
+	users   map[int64]struct{}
+}
+
+// botStats counts lookups per day since the bot start
+type botStats struct {
+	mu   sync.Mutex
+	days map[string]*dayStats
+}
+
+func newBotStats() *botStats {
+	return &botStats{days: make(map[string]*dayStats)}
+}
+
+// record counts one lookup of the user, err is the lookup result
+func (s *botStats) record(chatID int64, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	day := time.Now().Format("2006-01-02")
+	d, exists := s.days[day]
+	if !exists {
+		d = &dayStats{users: make(map[int64]struct{})}
+		s.days[day] = d
+	}
+
+	d.lookups++
+	if err != nil {
+		d.errors++
+	}
+	d.users[chatID] = struct{}{}
+}
+
+// report renders counters of the last days
+func (s *botStats) report(days int, lang i18n.Lang) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var sb strings.Builder
+	sb.WriteString(i18n.T(lang, "admin.stats") + "\n")
+
+	found := false
+	now := time.Now()
+	for i := 0; i < days; i++ {
+		day := now.AddDate(0, 0, -i).Format("2006-01-02")
+		d, exists := s.days[day]
+		if !exists {
+			continue
+		}
+		found = true
+
+		sb.WriteString("\n" + i18n.T(lang, "admin.stats_day",
+			day, d.lookups, d.errors, float64(d.errors)/float64(d.lookups)*100, len(d.users)))
+	}
+
+	if !found {
+		sb.WriteString("\n" + i18n.T(lang, "admin.stats_empty"))
+	}
+	return sb.String()
+}
@@ -0,0 +1,212 @@
+package tgBot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mashinki/alerts"
+	"mashinki/i18n"
+	"mashinki/logging"
+	"mashinki/search"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	cmdSubscribe     = "subscribe"
+	cmdSubscriptions = "subscriptions"
+
+	callbackSubPause  = "sub_pause:"
+	callbackSubResume = "sub_resume:"
+	callbackSubDelete = "sub_del:"
+
+	maxAlertCars = 10 // сколько новых машин присылается в одном уведомлении
+)
+
+// subscribe saves the search criteria of /subscribe
+func (b *Bot) subscribe(chatID int64, state *UserState, args string) tgbotapi.MessageConfig {
+	lang := b.lang(chatID)
+	reply := func(text string) tgbotapi.MessageConfig {
+		msg := tgbotapi.NewMessage(chatID, text)
+		msg.ReplyMarkup = mainKeyboard(lang)
+		return msg
+	}
+
+	criteria, err := search.ParseCriteria(args)
+	if err != nil {
+		return reply(i18n.T(lang, "subscribe.usage"))
+	}
+
+	sub, err := b.subscriptions.Add(alerts.Subscription{
+		ChatID:   chatID,
+		Criteria: criteria,
+		Profile:  state.CostProfile,
+		Lang:     lang,
+	})
+	switch {
+	case errors.Is(err, alerts.ErrTooMany):
+		return reply(i18n.T(lang, "subscribe.too_many", alerts.MaxPerUser))
+	case err != nil:
+		logging.DefaultLogger.LogErrorF("Error saving subscription: %v", err)
+		return reply(i18n.T(lang, "subscribe.error"))
+	}
+
+	msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "subscribe.added", sub.ID, sub.Criteria.String()))
+	msg.ReplyMarkup = subscriptionsKeyboard([]alerts.Subscription{sub}, lang)
+	return msg
+}
+
+// listSubscriptions shows subscriptions of the chat with buttons to manage them
+func (b *Bot) listSubscriptions(chatID int64) tgbotapi.MessageConfig {
+	text, keyboard := b.subscriptionsPage(chatID)
+	msg := tgbotapi.NewMessage(chatID, text)
+	if keyboard != nil {
+		msg.ReplyMarkup = *keyboard
+	} else {
+		msg.ReplyMarkup = mainKeyboard(b.lang(chatID))
+	}
+	return msg
+}
+
+// subscriptionsPage renders the list of subscriptions, keyboard is nil if there are none
+func (b *Bot) subscriptionsPage(chatID int64) (string, *tgbotapi.InlineKeyboardMarkup) {
+	lang := b.lang(chatID)
+	subs := b.subscriptions.List(chatID)
+	if len(subs) == 0 {
+		return i18n.T(lang, "subscribe.empty"), nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(i18n.T(lang, "subscribe.list") + "\n")
+	for _, sub := range subs {
+		mark := "🔔"
+		if sub.Paused {
+			mark = "⏸"
+		}
+		fmt.Fprintf(&sb, "\n%s #%d %s", mark, sub.ID, sub.Criteria.String())
+	}
+
+	keyboard := subscriptionsKeyboard(subs, lang)
+	return sb.String(), &keyboard
+}
+
+// subscriptionsKeyboard has pause or resume and delete buttons for every subscription
+func subscriptionsKeyboard(subs []alerts.Subscription, lang i18n.Lang) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, sub := range subs {
+		id := strconv.FormatInt(sub.ID, 10)
+		toggle := tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "subscribe.pause", sub.ID), callbackSubPause+id)
+		if sub.Paused {
+			toggle = tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "subscribe.resume", sub.ID), callbackSubResume+id)
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			toggle,
+			tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "subscribe.delete", sub.ID), callbackSubDelete+id),
+		))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// manageSubscription pauses, resumes or deletes the subscription by the callback data
+// and edits the message to show the updated list
+func (b *Bot) manageSubscription(chatID int64, messageID int, data string) {
+	var (
+		action string
+		ok     bool
+		err    error
+	)
+	for _, prefix := range []string{callbackSubPause, callbackSubResume, callbackSubDelete} {
+		if strings.HasPrefix(data, prefix) {
+			action = prefix
+		}
+	}
+	id, parseErr := strconv.ParseInt(strings.TrimPrefix(data, action), 10, 64)
+	if parseErr != nil {
+		return
+	}
+
+	switch action {
+	case callbackSubPause:
+		ok, err = b.subscriptions.SetPaused(chatID, id, true)
+	case callbackSubResume:
+		ok, err = b.subscriptions.SetPaused(chatID, id, false)
+	case callbackSubDelete:
+		ok, err = b.subscriptions.Remove(chatID, id)
+	}
+	if err != nil {
+		logging.DefaultLogger.LogErrorF("Error updating subscription %d: %v", id, err)
+		b.sendText(chatID, i18n.T(b.lang(chatID), "subscribe.error"))
+		return
+	}
+	if !ok {
+		b.sendText(chatID, i18n.T(b.lang(chatID), "subscribe.not_found"))
+	}
+
+	text, keyboard := b.subscriptionsPage(chatID)
+	var edit tgbotapi.EditMessageTextConfig
+	if keyboard != nil {
+		edit = tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID, text, *keyboard)
+	} else {
+		edit = tgbotapi.NewEditMessageText(chatID, messageID, text)
+	}
+	if _, err := b.api.Send(edit); err != nil {
+		logging.DefaultLogger.LogErrorF("Error editing subscriptions: %v", err)
+	}
+}
+
+// listForSubscription returns IDs of listings existing when the subscription was created
+func (b *Bot) listForSubscription(ctx context.Context, sub alerts.Subscription) ([]string, error) {
+	if err := b.queue.Wait(ctx, nil); err != nil {
+		return nil, err
+	}
+	defer b.queue.Done()
+
+	return search.New(subscriptionLang(sub).TranslationTarget()).ListingIDs(ctx, sub.Criteria)
+}
+
+// findForSubscription searches new cars of the subscription sharing workers with user lookups
+func (b *Bot) findForSubscription(ctx context.Context, sub alerts.Subscription) (search.Found, error) {
+	if err := b.queue.Wait(ctx, nil); err != nil {
+		return search.Found{}, err
+	}
+	defer b.queue.Done()
+
+	return search.New(subscriptionLang(sub).TranslationTarget()).Find(ctx, sub.Criteria, sub.Profile, sub.IsSeen)
+}
+
+// notifySubscription sends new cars of the subscription
+func (b *Bot) notifySubscription(sub alerts.Subscription, cars []search.Car) {
+	msg := tgbotapi.NewMessage(sub.ChatID, subscriptionAlert(sub, cars))
+	msg.ReplyMarkup = subscriptionsKeyboard([]alerts.Subscription{sub}, subscriptionLang(sub))
+	msg.DisableWebPagePreview = true
+	if _, err := b.api.Send(msg); err != nil {
+		logging.DefaultLogger.LogErrorF("Error sending subscription %d alert: %v", sub.ID, err)
+	}
+}
+
+// subscriptionAlert renders new cars of the subscription, the cheapest first
+func subscriptionAlert(sub alerts.Subscription, cars []search.Car) string {
+	lang := subscriptionLang(sub)
+
+	var sb strings.Builder
+	sb.WriteString("🔔 " + i18n.T(lang, "subscribe.new", sub.ID, len(cars)) + "\n" + sub.Criteria.String() + "\n")
+	for i, car := range cars {
+		if i == maxAlertCars {
+			sb.WriteString("\n" + i18n.T(lang, "subscribe.more", len(cars)-maxAlertCars))
+			break
+		}
+		r := car.Result
+		fmt.Fprintf(&sb, "\n%d. %s\n📅 %s  📊 %s\n💰 ¥%.0f → 💵 %.2f ₽\n🔗 %s\n",
+			i+1, r.Car.FullName, r.Car.Year, r.Car.Mileage.Format(i18n.T(lang, "unit.km")), r.Car.Price, r.Total, car.Listing.URL)
+	}
+	return sb.String()
+}
+
+func subscriptionLang(sub alerts.Subscription) i18n.Lang {
+	if sub.Lang == "" {
+		return i18n.Default
+	}
+	return sub.Lang
+}
@@ -0,0 +1,65 @@
+package tgBot
+
+import (
+	"fmt"
+	"mashinki/alerts"
+	"mashinki/i18n"
+	"mashinki/parser"
+	"mashinki/search"
+	"mashinki/storage"
+	"mashinki/taxes"
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestSubscribe(t *testing.T) {
+	store, err := alerts.NewStore(storage.Open(t.TempDir(), "subscriptions.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &Bot{userStates: make(map[int64]*UserState), subscriptions: store}
+	state := b.getUserState(42)
+	state.CostProfile = "moscow"
+
+	if msg := b.subscribe(42, state, ""); msg.Text != i18n.T(i18n.Default, "subscribe.usage") {
+		t.Errorf("expected usage, got %q", msg.Text)
+	}
+
+	msg := b.subscribe(42, state, "brand=aodi total=3000000")
+	if msg.Text != i18n.T(i18n.Default, "subscribe.added", 1, "brand=aodi total=3000000") {
+		t.Errorf("unexpected answer %q", msg.Text)
+	}
+	subs := store.List(42)
+	if len(subs) != 1 || subs[0].Profile != "moscow" || subs[0].Lang != i18n.Default {
+		t.Fatalf("unexpected subscriptions %+v", subs)
+	}
+
+	store.SetPaused(42, subs[0].ID, true)
+	msg = b.listSubscriptions(42)
+	keyboard, ok := msg.ReplyMarkup.(tgbotapi.InlineKeyboardMarkup)
+	if !ok || !strings.Contains(msg.Text, "⏸ #1 brand=aodi") || *keyboard.InlineKeyboard[0][0].CallbackData != callbackSubResume+"1" {
+		t.Errorf("unexpected list %q, %+v", msg.Text, msg.ReplyMarkup)
+	}
+
+	if msg = b.listSubscriptions(7); msg.Text != i18n.T(i18n.Default, "subscribe.empty") {
+		t.Errorf("expected no subscriptions, got %q", msg.Text)
+	}
+}
+
+func TestSubscriptionAlert(t *testing.T) {
+	var cars []search.Car
+	for i := 0; i < maxAlertCars+2; i++ {
+		cars = append(cars, search.Car{
+			Listing: parser.Listing{Name: fmt.Sprintf("车 %d", i), URL: "https://www.che168.com/dealer/1/2.html"},
+			Result:  taxes.Result{Car: parser.CarInfo{FullName: fmt.Sprintf("Car %d", i)}},
+		})
+	}
+
+	text := subscriptionAlert(alerts.Subscription{ID: 3, Lang: i18n.English}, cars)
+	if !strings.HasPrefix(text, "🔔 "+i18n.T(i18n.English, "subscribe.new", 3, len(cars))) ||
+		!strings.Contains(text, i18n.T(i18n.English, "subscribe.more", 2)) || strings.Contains(text, "Car 10") || !strings.Contains(text, "\n1. Car 0\n") {
+		t.Errorf("unexpected alert:\n%s", text)
+	}
+}
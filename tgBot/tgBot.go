@@ -2,33 +2,75 @@ package tgBot
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"mashinki/alerts"
+	"mashinki/bulk"
 	envhandler "mashinki/envHandler"
+	"mashinki/history"
+	"mashinki/i18n"
 	"mashinki/logging"
+	"mashinki/market"
 	"mashinki/parser"
+	"mashinki/ratelimit"
+	"mashinki/render"
+	"mashinki/reputation"
+	"mashinki/search"
+	"mashinki/storage"
 	"mashinki/taxes"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
+// maxHandlers limits updates handled at the same time
+const maxHandlers = 100
+
 const (
-	cmdStart   = "start"
-	btnFindCar = "🚗 Найти информацию о машине"
+	cmdStart    = "start"
+	cmdCompare  = "compare"
+	cmdRoute    = "route"
+	cmdLanguage = "language"
+
+	// button message keys
+	btnFindCar = "btn.find_car"
+	btnCompare = "btn.compare"
+	btnBulk    = "btn.bulk"
+
+	maxCompareCars = 5
 )
 
-var (
-	// Ading button
-	mainKeyboard = tgbotapi.NewReplyKeyboard(
+var urlRegexp = regexp.MustCompile(`https?://\S+`)
+
+// mainKeyboard returns the main buttons in the language
+func mainKeyboard(lang i18n.Lang) tgbotapi.ReplyKeyboardMarkup {
+	return tgbotapi.NewReplyKeyboard(
 		tgbotapi.NewKeyboardButtonRow(
-			tgbotapi.NewKeyboardButton(btnFindCar),
+			tgbotapi.NewKeyboardButton(i18n.T(lang, btnFindCar)),
+		),
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton(i18n.T(lang, btnCompare)),
+			tgbotapi.NewKeyboardButton(i18n.T(lang, btnBulk)),
 		),
 	)
-)
+}
 
 // Состояния пользователя
 type UserState struct {
-	WaitingForURL bool
+	WaitingForURL     bool
+	WaitingForCompare bool
+	CostProfile       string          // выбранный маршрут доставки
+	LastResult        *taxes.Result   // последний расчет для КП
+	Language          i18n.Lang       // язык интерфейса
+	SearchResults     []search.Car    // последние результаты поиска
+	PendingCar        *parser.CarInfo // машина, для расчета которой не хватает данных
 }
 
 type Bot struct {
@@ -36,6 +78,37 @@ type Bot struct {
 	cancel      context.CancelFunc
 	userStates  map[int64]*UserState
 	statesMutex sync.RWMutex
+	brand       string // название компании в КП
+	limiter     *ratelimit.Limiter
+	queue       *ratelimit.Queue
+	access      *access
+	stats       *botStats
+
+	subscriptions *alerts.Store
+
+	photos        *photoCache // nil if photos are not sent
+	photoSize     parser.PhotoSize
+	downloadPhoto func(url string) ([]byte, error)
+
+	inspection func(carID string) (parser.InspectionReport, error) // nil if reports are not requested
+
+	dealers *reputation.Store // nil if dealer reputation is not kept
+	market  *market.Analyzer
+	history *history.Store // nil if lookups are not kept
+	layout  *layoutWatch   // nil if admins are not alerted about che168 markup changes
+
+	webhookServer *http.Server    // nil in long polling mode
+	webhook       *webhookHandler // nil in long polling mode
+
+	// in-flight work is drained on shutdown
+	runDone         chan struct{} // closed when updates are no longer dispatched
+	workCtx         context.Context
+	cancelWork      context.CancelFunc
+	handlers        sync.WaitGroup
+	lastUpdateID    atomic.Int64
+	inFlight        map[int64]int
+	inFlightMu      sync.Mutex
+	shutdownTimeout time.Duration
 }
 
 func StartBot() (*Bot, error) {
@@ -47,23 +120,98 @@ func StartBot() (*Bot, error) {
 
 	log.Printf("Authorized on account %s", api.Self.UserName)
 
+	limits, workers := limitsConfig()
+
+	dataDir := envhandler.GetEnv("DATA_DIR")
+	if dataDir == "" {
+		dataDir = storage.DefaultDir
+	}
+	subscriptions, err := alerts.NewStore(storage.Open(dataDir, "subscriptions.json"))
+	if err != nil {
+		return nil, err
+	}
+	photos, err := newPhotoCache(storage.Open(dataDir, "photos.json"))
+	if err != nil {
+		return nil, err
+	}
+	dealers, err := reputation.NewStore(storage.Open(dataDir, "dealers.json"))
+	if err != nil {
+		return nil, err
+	}
+	samples, err := market.NewStore(storage.Open(dataDir, "market.json"))
+	if err != nil {
+		return nil, err
+	}
+	prices, err := history.NewStore(storage.Open(dataDir, "history.json"))
+	if err != nil {
+		return nil, err
+	}
+	access, err := newAccess(storage.Open(dataDir, "access.json"))
+	if err != nil {
+		return nil, err
+	}
+	limiter := ratelimit.NewLimiter(limits)
+	access.applyBans(limiter, time.Now())
+
 	ctx, cancel := context.WithCancel(context.Background())
+	workCtx, cancelWork := context.WithCancel(context.Background())
 	bot := &Bot{
 		api:         api,
 		cancel:      cancel,
 		userStates:  make(map[int64]*UserState),
 		statesMutex: sync.RWMutex{},
+		brand:       envhandler.GetEnv("QUOTE_BRAND"),
+		limiter:     limiter,
+		queue:       ratelimit.NewQueue(workers),
+		access:      access,
+		stats:       newBotStats(),
+
+		subscriptions: subscriptions,
+
+		photos:        photos,
+		photoSize:     parser.ParsePhotoSize(envhandler.GetEnv("PHOTO_SIZE")),
+		downloadPhoto: parser.DownloadPhoto,
+		inspection:    parser.GetInspectionReport,
+		dealers:       dealers,
+		market:        market.NewAnalyzer(samples),
+		history:       prices,
+		layout:        &layoutWatch{dir: filepath.Join(dataDir, "layout")},
+
+		runDone:         make(chan struct{}),
+		workCtx:         workCtx,
+		cancelWork:      cancelWork,
+		inFlight:        make(map[int64]int),
+		shutdownTimeout: time.Duration(envhandler.GetEnvInt("SHUTDOWN_TIMEOUT_SECONDS", 30)) * time.Second,
 	}
 
-	go bot.run(ctx)
+	// lookups of the bot, the API and bulk files alert admins about markup changes
+	parser.OnLayoutChange(bot.layoutChanged)
 
-	return bot, nil
-}
+	var updates tgbotapi.UpdatesChannel
+	if webhook := webhookConfigFromEnv(); webhook.URL != "" {
+		updates, err = bot.startWebhook(webhook)
+		if err != nil {
+			cancel()
+			cancelWork()
+			return nil, err
+		}
+	} else {
+		// getUpdates doesn't work while a webhook is set
+		if _, err := api.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+			logging.DefaultLogger.LogErrorF("Error deleting webhook: %v", err)
+		}
 
-func (b *Bot) Stop() {
-	if b.cancel != nil {
-		b.cancel()
+		u := tgbotapi.NewUpdate(0)
+		u.Timeout = 60
+		updates = api.GetUpdatesChan(u)
 	}
+
+	interval := time.Duration(envhandler.GetEnvInt("SUBSCRIPTION_INTERVAL_MINUTES", 30)) * time.Minute
+	go alerts.NewScheduler(subscriptions, interval, bot.listForSubscription, bot.findForSubscription, bot.notifySubscription).Run(ctx)
+
+	go bot.run(ctx, updates)
+
+	return bot, nil
 }
 
 func (b *Bot) getUserState(chatID int64) *UserState {
@@ -88,48 +236,168 @@ func (b *Bot) setUserState(chatID int64, state *UserState) {
 }
 
 func (b *Bot) handleMessage(ctx context.Context, update tgbotapi.Update) {
+	if update.CallbackQuery != nil {
+		b.handleCallback(ctx, update.CallbackQuery)
+		return
+	}
 	if update.Message == nil {
 		return
 	}
 
+	if update.Message.IsCommand() && b.handleAdminCommand(update.Message) {
+		return
+	}
+
 	chatID := update.Message.Chat.ID
 	state := b.getUserState(chatID)
+	lang := b.lang(chatID)
+	text := update.Message.Text
 
 	var msg tgbotapi.MessageConfig
 
 	switch {
 	case update.Message.Command() == cmdStart:
-		msg = tgbotapi.NewMessage(chatID, "Привет! Я помогу найти информацию о машине и рассчитаю таможенные платежи. Нажми на кнопку ниже:")
-		msg.ReplyMarkup = mainKeyboard
+		msg = tgbotapi.NewMessage(chatID, i18n.T(lang, "start"))
+		msg.ReplyMarkup = mainKeyboard(lang)
+
+	case update.Message.Command() == cmdLanguage:
+		msg = b.selectLanguage(chatID, state, update.Message.CommandArguments())
+
+	case update.Message.Command() == cmdCompare && update.Message.CommandArguments() != "":
+		state.WaitingForURL = false
+		state.WaitingForCompare = false
+		b.setUserState(chatID, state)
+		if !b.acquireLookup(ctx, chatID) {
+			return
+		}
+		defer b.releaseLookup(chatID)
+		msg = b.compareCars(chatID, update.Message.CommandArguments(), state.CostProfile)
+
+	case update.Message.Document != nil:
+		state.WaitingForURL = false
+		state.WaitingForCompare = false
+		b.setUserState(chatID, state)
+		if !b.acquireLookup(ctx, chatID) {
+			return
+		}
+		defer b.releaseLookup(chatID)
+		b.processBulkFile(ctx, chatID, update.Message.Document, state.CostProfile)
+		return
+
+	case i18n.Matches(text, btnBulk):
+		msg = tgbotapi.NewMessage(chatID, i18n.T(lang, "bulk.prompt", bulk.MaxURLs))
+		msg.ReplyMarkup = mainKeyboard(lang)
+
+	case update.Message.Command() == cmdRoute:
+		msg = b.selectRoute(chatID, state, update.Message.CommandArguments())
+
+	case update.Message.Command() == cmdCountry:
+		msg = b.selectCountry(chatID, state, update.Message.CommandArguments())
+
+	case update.Message.Command() == cmdSearch:
+		state.WaitingForURL = false
+		state.WaitingForCompare = false
+		b.setUserState(chatID, state)
+		if !b.acquireLookup(ctx, chatID) {
+			return
+		}
+		defer b.releaseLookup(chatID)
+		msg = b.searchCars(ctx, chatID, state, update.Message.CommandArguments())
+		if ctx.Err() != nil {
+			// the user is notified about cancelled requests on shutdown
+			return
+		}
+
+	case update.Message.Command() == cmdSubscribe:
+		msg = b.subscribe(chatID, state, update.Message.CommandArguments())
 
-	case update.Message.Text == btnFindCar:
+	case update.Message.Command() == cmdSubscriptions:
+		msg = b.listSubscriptions(chatID)
+
+	case update.Message.Command() == cmdFill:
+		msg = b.fillCar(chatID, state, update.Message.CommandArguments())
+
+	case update.Message.Command() == cmdPriceHistory:
+		b.sendPriceHistory(chatID, update.Message.CommandArguments())
+		return
+
+	case update.Message.Command() == cmdBudget:
+		msg = b.findMaxPrice(chatID, state, update.Message.CommandArguments())
+
+	case i18n.Matches(text, btnFindCar):
 		state.WaitingForURL = true
+		state.WaitingForCompare = false
+		b.setUserState(chatID, state)
+		msg = tgbotapi.NewMessage(chatID, i18n.T(lang, "lookup.prompt"))
+
+	case update.Message.Command() == cmdCompare || i18n.Matches(text, btnCompare):
+		state.WaitingForCompare = true
+		state.WaitingForURL = false
 		b.setUserState(chatID, state)
-		msg = tgbotapi.NewMessage(chatID, "Отправь мне ссылку на машину с сайта che168.com")
+		msg = tgbotapi.NewMessage(chatID, i18n.T(lang, "compare.prompt", maxCompareCars))
+
+	case state.WaitingForCompare:
+		state.WaitingForCompare = false
+		b.setUserState(chatID, state)
+		if !b.acquireLookup(ctx, chatID) {
+			return
+		}
+		defer b.releaseLookup(chatID)
+		msg = b.compareCars(chatID, text, state.CostProfile)
 
 	case state.WaitingForURL:
 		state.WaitingForURL = false
 		b.setUserState(chatID, state)
+		if !b.acquireLookup(ctx, chatID) {
+			return
+		}
+		defer b.releaseLookup(chatID)
 
-		processingMsg := tgbotapi.NewMessage(chatID, "🔄 Получаю информацию о машине и рассчитываю таможенные платежи...")
+		processingMsg := tgbotapi.NewMessage(chatID, i18n.T(lang, "lookup.processing"))
 		if _, err := b.api.Send(processingMsg); err != nil {
 			logging.DefaultLogger.LogErrorF("Error sending processing message: %v", err)
 		}
 
-		carInfo, err := parser.GetCarInfo(update.Message.Text)
-		if err != nil {
+		carInfo, err := parser.GetCarInfoIn(text, lang.TranslationTarget())
+		b.stats.record(chatID, err)
+		switch {
+		case errors.Is(err, parser.ErrListingRemoved):
+			b.recordRemoved(text)
+			msg = tgbotapi.NewMessage(chatID, i18n.T(lang, "lookup.removed"))
+			msg.ReplyMarkup = mainKeyboard(lang)
+		case errors.Is(err, parser.ErrLayoutChanged):
+			msg = tgbotapi.NewMessage(chatID, i18n.T(lang, "lookup.layout_changed"))
+			msg.ReplyMarkup = mainKeyboard(lang)
+		case err != nil:
 			logging.DefaultLogger.LogErrorF("Error getting car info: %v", err)
-			msg = tgbotapi.NewMessage(chatID, "❌ Ошибка при получении информации о машине")
-		} else {
-			fullInfo := taxes.NewFullCarInfo(&carInfo)
-			msg = tgbotapi.NewMessage(chatID, "✅ "+fullInfo.String())
+			msg = tgbotapi.NewMessage(chatID, i18n.T(lang, "lookup.error"))
+			msg.ReplyMarkup = mainKeyboard(lang)
+		default:
+			b.recordDealer(carInfo.Seller, carInfo.CarId, reputation.OutcomeOK)
+			b.recordHistory(carInfo)
+			b.sendPhotos(ctx, chatID, carInfo)
+
+			result, err := taxes.CalculateChecked(carInfo, state.CostProfile)
+			if err != nil {
+				state.PendingCar = &carInfo
+				b.setUserState(chatID, state)
+				msg = tgbotapi.NewMessage(chatID, insufficientText(err, lang))
+				msg.ReplyMarkup = mainKeyboard(lang)
+				break
+			}
+			state.PendingCar = nil
+			state.LastResult = &result
+			b.setUserState(chatID, state)
+
+			msg = tgbotapi.NewMessage(chatID, "✅ "+render.Markdown(result, lang)+b.riskSummary(carInfo.CarId, lang)+
+				b.dealerWarning(carInfo.Seller, lang)+issuesText(carInfo, lang))
+			msg.ReplyMarkup = quoteKeyboard(result.Car, lang)
 		}
-		msg.ReplyMarkup = mainKeyboard
 		msg.ParseMode = "Markdown"
 
 	default:
-		msg = tgbotapi.NewMessage(chatID, "Нажми на кнопку ниже, чтобы начать:")
-		msg.ReplyMarkup = mainKeyboard
+		msg = tgbotapi.NewMessage(chatID, i18n.T(lang, "default"))
+		msg.ReplyMarkup = mainKeyboard(lang)
 	}
 
 	if _, err := b.api.Send(msg); err != nil {
@@ -137,26 +405,129 @@ func (b *Bot) handleMessage(ctx context.Context, update tgbotapi.Update) {
 	}
 }
 
-func (b *Bot) run(ctx context.Context) {
-	u := tgbotapi.NewUpdate(0)
-	u.Timeout = 60
+// selectRoute sets user's cost profile or lists available ones
+func (b *Bot) selectRoute(chatID int64, state *UserState, name string) tgbotapi.MessageConfig {
+	name = strings.TrimSpace(name)
+
+	if name != "" {
+		if profile, ok := taxes.GetCostProfile(name); ok {
+			state.CostProfile = profile.Name
+			b.setUserState(chatID, state)
+			msg := tgbotapi.NewMessage(chatID, i18n.T(state.Language, "route.selected", profile.Title))
+			msg.ReplyMarkup = mainKeyboard(state.Language)
+			return msg
+		}
+	}
+
+	current, _ := taxes.GetCostProfile(state.CostProfile)
+	text := i18n.T(state.Language, "route.list", current.Title)
+	for _, profile := range taxes.CostProfiles() {
+		text += fmt.Sprintf("/%s %s — %s\n", cmdRoute, profile.Name, profile.Title)
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = mainKeyboard(state.Language)
+	return msg
+}
+
+// compareCars fetches all cars from the links in text and builds a comparison message
+func (b *Bot) compareCars(chatID int64, text string, profile string) tgbotapi.MessageConfig {
+	lang := b.lang(chatID)
+
+	urls := urlRegexp.FindAllString(text, -1)
+	if len(urls) < 2 || len(urls) > maxCompareCars {
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "compare.bad_count", maxCompareCars))
+		msg.ReplyMarkup = mainKeyboard(lang)
+		return msg
+	}
+
+	processingMsg := tgbotapi.NewMessage(chatID, i18n.T(lang, "compare.processing"))
+	if _, err := b.api.Send(processingMsg); err != nil {
+		logging.DefaultLogger.LogErrorF("Error sending processing message: %v", err)
+	}
+
+	carInfos, errs := parser.GetCarsInfo(urls, lang.TranslationTarget())
+
+	var results []taxes.Result
+	var failed string
+	for i := range carInfos {
+		b.stats.record(chatID, errs[i])
+		if errs[i] != nil {
+			logging.DefaultLogger.LogErrorF("Error getting car info for %s: %v", urls[i], errs[i])
+			failed += "\n" + i18n.T(lang, "compare.failed_car", i+1)
+			continue
+		}
+		b.recordHistory(carInfos[i])
+		result, err := taxes.CalculateChecked(carInfos[i], profile)
+		if err != nil {
+			failed += "\n" + i18n.T(lang, "compare.failed_car", i+1)
+			continue
+		}
+		results = append(results, result)
+	}
+
+	msg := tgbotapi.NewMessage(chatID, compareText(results, failed, lang))
+	if len(results) >= 2 {
+		msg.ParseMode = "Markdown"
+	}
+	msg.ReplyMarkup = mainKeyboard(lang)
+	return msg
+}
+
+// compareText is the comparison of results followed by the failed cars.
+// With less than two results there is nothing to compare, so only the failures are listed.
+func compareText(results []taxes.Result, failed string, lang i18n.Lang) string {
+	switch len(results) {
+	case 0:
+		return i18n.T(lang, "compare.error") + failed
+	case 1:
+		return i18n.T(lang, "compare.too_few", len(results)) + failed
+	}
+	return render.CompareMarkdown(results, lang) + failed
+}
 
-	updates := b.api.GetUpdatesChan(u)
+// run dispatches updates until the context is cancelled or the channel is closed.
+// In webhook mode the channel is closed on shutdown after all accepted updates are read.
+func (b *Bot) run(ctx context.Context, updates tgbotapi.UpdatesChannel) {
+	defer close(b.runDone)
+	if b.webhook != nil {
+		// accepted webhook updates are not resent by Telegram, so they are drained
+		ctx = context.Background()
+	}
 
-	// Making 100 chans for users
-	const maxWorkers = 100
-	sem := make(chan struct{}, maxWorkers)
+	// at most maxHandlers updates are handled at the same time, the rest wait in the channel
+	slots := make(chan struct{}, maxHandlers)
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case update := <-updates:
-			// Start a new goroutine for each update
-			sem <- struct{}{}
+		case update, ok := <-updates:
+			if !ok {
+				return
+			}
+			// rejected updates are confirmed too, so they are not received again after restart
+			b.lastUpdateID.Store(int64(update.UpdateID))
+
+			b.detectLanguage(update)
+			if !b.checkLimits(update) || !b.checkAccess(update) {
+				continue
+			}
+
+			// Start a new goroutine for each update,
+			// heavy lookups are limited by the queue inside
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			b.handlers.Add(1)
 			go func(update tgbotapi.Update) {
-				defer func() { <-sem }()
-				b.handleMessage(ctx, update)
+				defer func() {
+					<-slots
+					b.handlers.Done()
+				}()
+				b.handleMessage(b.workCtx, update)
 			}(update)
 		}
 	}
@@ -0,0 +1,28 @@
+package tgBot
+
+import (
+	"mashinki/i18n"
+	"mashinki/parser"
+	"mashinki/taxes"
+	"strings"
+	"testing"
+)
+
+func TestCompareText(t *testing.T) {
+	failed := "\n" + i18n.T(i18n.English, "compare.failed_car", 2)
+	car := taxes.Calculate(parser.CarInfo{FullName: "Test", Year: "2020-01", Price: 100_000, EngineSize: 1598}, "")
+
+	text := compareText([]taxes.Result{car}, failed, i18n.English)
+	if strings.Contains(text, "🏆") || !strings.Contains(text, "#2") {
+		t.Errorf("one car must not be compared and the failed link must be reported, got:\n%s", text)
+	}
+
+	if text := compareText(nil, failed, i18n.English); !strings.Contains(text, "#2") {
+		t.Errorf("failed links must be reported without results, got:\n%s", text)
+	}
+
+	text = compareText([]taxes.Result{car, car}, failed, i18n.English)
+	if !strings.Contains(text, i18n.T(i18n.English, "compare.title")) || !strings.HasSuffix(text, failed) {
+		t.Errorf("unexpected comparison:\n%s", text)
+	}
+}
@@ -0,0 +1,170 @@
+package tgBot
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	envhandler "mashinki/envHandler"
+	"mashinki/logging"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const secretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// webhookConfig describes the webhook mode, it is used instead of long polling if URL is set
+type webhookConfig struct {
+	URL      string // public URL Telegram sends updates to
+	Listen   string // address of the HTTP listener
+	Secret   string // secret token checked in every request
+	CertFile string // TLS certificate, empty if TLS is terminated by a reverse proxy
+	KeyFile  string // TLS key
+}
+
+func webhookConfigFromEnv() webhookConfig {
+	cfg := webhookConfig{
+		URL:      envhandler.GetEnv("WEBHOOK_URL"),
+		Listen:   envhandler.GetEnv("WEBHOOK_LISTEN"),
+		Secret:   envhandler.GetEnv("WEBHOOK_SECRET"),
+		CertFile: envhandler.GetEnv("WEBHOOK_CERT"),
+		KeyFile:  envhandler.GetEnv("WEBHOOK_KEY"),
+	}
+	if cfg.Listen == "" {
+		cfg.Listen = ":8443"
+	}
+	return cfg
+}
+
+// webhookHandler receives updates sent by Telegram.
+// After close it answers 503, so Telegram resends updates to the next instance.
+type webhookHandler struct {
+	secret  string
+	updates chan<- tgbotapi.Update
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// close stops accepting updates and closes the channel,
+// updates accepted before stay in the channel to be processed
+func (h *webhookHandler) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.closed {
+		h.closed = true
+		close(h.updates)
+	}
+}
+
+func (h *webhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := r.Header.Get(secretTokenHeader)
+	if h.secret != "" && subtle.ConstantTimeCompare([]byte(token), []byte(h.secret)) != 1 {
+		http.Error(w, "invalid secret token", http.StatusUnauthorized)
+		return
+	}
+
+	var update tgbotapi.Update
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&update); err != nil {
+		http.Error(w, "invalid update", http.StatusBadRequest)
+		return
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.closed {
+		http.Error(w, "shutting down", http.StatusServiceUnavailable)
+		return
+	}
+
+	select {
+	case h.updates <- update:
+		w.WriteHeader(http.StatusOK)
+	case <-r.Context().Done():
+		// Telegram will resend the update
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+}
+
+// startWebhook registers the webhook in Telegram and starts the HTTP listener
+func (b *Bot) startWebhook(cfg webhookConfig) (tgbotapi.UpdatesChannel, error) {
+	webhookURL, err := url.Parse(cfg.URL)
+	if err != nil || webhookURL.Scheme != "https" {
+		return nil, fmt.Errorf("WEBHOOK_URL must be a valid https url")
+	}
+	if cfg.Secret == "" {
+		return nil, errors.New("WEBHOOK_SECRET is required in webhook mode")
+	}
+
+	updates := make(chan tgbotapi.Update, b.api.Buffer)
+
+	path := webhookURL.Path
+	if path == "" {
+		path = "/"
+	}
+	b.webhook = &webhookHandler{secret: cfg.Secret, updates: updates}
+	mux := http.NewServeMux()
+	mux.Handle(path, b.webhook)
+
+	b.webhookServer = &http.Server{
+		Addr:              cfg.Listen,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		log.Printf("Webhook listening on %s", cfg.Listen)
+		var err error
+		if cfg.CertFile != "" {
+			err = b.webhookServer.ListenAndServeTLS(cfg.CertFile, cfg.KeyFile)
+		} else {
+			err = b.webhookServer.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logging.DefaultLogger.LogErrorF("Webhook server error: %v", err)
+		}
+	}()
+
+	params := tgbotapi.Params{"url": webhookURL.String()}
+	params.AddNonEmpty("secret_token", cfg.Secret)
+
+	// Uploading self-signed certificate so Telegram trusts it
+	if cfg.CertFile != "" {
+		files := []tgbotapi.RequestFile{{Name: "certificate", Data: tgbotapi.FilePath(cfg.CertFile)}}
+		_, err = b.api.UploadFiles("setWebhook", params, files)
+	} else {
+		_, err = b.api.MakeRequest("setWebhook", params)
+	}
+	if err != nil {
+		b.stopWebhook()
+		return nil, fmt.Errorf("error while setting webhook: %v", err)
+	}
+
+	return updates, nil
+}
+
+// stopWebhook removes the webhook from Telegram, stops the HTTP listener
+// and closes the channel of updates
+func (b *Bot) stopWebhook() {
+	if _, err := b.api.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+		logging.DefaultLogger.LogErrorF("Error deleting webhook: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := b.webhookServer.Shutdown(ctx); err != nil {
+		logging.DefaultLogger.LogErrorF("Error stopping webhook server: %v", err)
+	}
+	b.webhook.close()
+}
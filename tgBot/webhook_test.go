@@ -0,0 +1,112 @@
+package tgBot
+
+import (
+	"context"
+	"encoding/json"
+	"mashinki/ratelimit"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const testUpdate = `{
+	"update_id": 1001,
+	"message": {
+		"message_id": 7,
+		"from": {"id": 42, "is_bot": false, "first_name": "Test"},
+		"chat": {"id": 42, "type": "private"},
+		"date": 1700000000,
+		"text": "https://www.che168.com/dealer/1/12345.html"
+	}
+}`
+
+func TestWebhookHandler(t *testing.T) {
+	updates := make(chan tgbotapi.Update, 1)
+	h := &webhookHandler{secret: "secret", updates: updates}
+
+	tests := []struct {
+		name   string
+		method string
+		secret string
+		body   string
+		status int
+	}{
+		{"wrong method", http.MethodGet, "secret", "", http.StatusMethodNotAllowed},
+		{"no secret", http.MethodPost, "", testUpdate, http.StatusUnauthorized},
+		{"wrong secret", http.MethodPost, "wrong", testUpdate, http.StatusUnauthorized},
+		{"broken update", http.MethodPost, "secret", `{"update_id": `, http.StatusBadRequest},
+		{"ok", http.MethodPost, "secret", testUpdate, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/webhook", strings.NewReader(tt.body))
+			if tt.secret != "" {
+				req.Header.Set(secretTokenHeader, tt.secret)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, rec.Code)
+			}
+		})
+	}
+
+	select {
+	case update := <-updates:
+		if update.UpdateID != 1001 || update.Message == nil || update.Message.Chat.ID != 42 {
+			t.Errorf("unexpected update: %+v", update)
+		}
+		if !strings.Contains(update.Message.Text, "che168.com") {
+			t.Errorf("unexpected message text: %q", update.Message.Text)
+		}
+	default:
+		t.Fatalf("update was not delivered")
+	}
+
+	if len(updates) != 0 {
+		t.Errorf("rejected requests must not deliver updates")
+	}
+}
+
+func TestWebhookShutdown(t *testing.T) {
+	updates := make(chan tgbotapi.Update, 1)
+	h := &webhookHandler{secret: "secret", updates: updates}
+
+	var update tgbotapi.Update
+	if err := json.Unmarshal([]byte(testUpdate), &update); err != nil {
+		t.Fatal(err)
+	}
+	updates <- update
+	h.close()
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(testUpdate))
+	req.Header.Set(secretTokenHeader, "secret")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status %d after shutdown, got %d", http.StatusServiceUnavailable, rec.Code)
+	}
+
+	// the accepted update is read even though the bot is stopping
+	limiter := ratelimit.NewLimiter(ratelimit.DefaultConfig())
+	limiter.Ban(42, time.Hour)
+	b := &Bot{userStates: make(map[int64]*UserState), limiter: limiter, webhook: h, runDone: make(chan struct{})}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	b.run(ctx, updates)
+
+	if id := b.lastUpdateID.Load(); id != 1001 {
+		t.Errorf("rejected update should be confirmed, got last update %d", id)
+	}
+	select {
+	case <-b.runDone:
+	default:
+		t.Errorf("run should report that it has stopped")
+	}
+}
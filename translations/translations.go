@@ -7,8 +7,53 @@ import (
 	"io"
 	"mashinki/logging"
 	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
 )
 
+const libreTranslateURL = "http://localhost:5000"
+
+var (
+	// glossary holds fixed translations of known terms
+	glossary   = map[string]string{}
+	glossaryMu sync.RWMutex
+)
+
+// LoadGlossary replaces the glossary with Chinese to Russian terms from the JSON file
+func LoadGlossary(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error while reading glossary: %v", err)
+	}
+
+	terms := map[string]string{}
+	if err := json.Unmarshal(data, &terms); err != nil {
+		return fmt.Errorf("error while parsing glossary: %v", err)
+	}
+
+	glossaryMu.Lock()
+	glossary = terms
+	glossaryMu.Unlock()
+	return nil
+}
+
+// CheckHealth checks that LibreTranslate is available
+func CheckHealth() error {
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get(libreTranslateURL)
+	if err != nil {
+		return fmt.Errorf("error while sending request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("server returned status %d instead of 200 OK", resp.StatusCode)
+	}
+	return nil
+}
+
 func translateByLibreTranslate(text string, sourceLang string, targetLang string) (string, error) {
 	// forming the request data
 	data := map[string]interface{}{
@@ -23,7 +68,7 @@ func translateByLibreTranslate(text string, sourceLang string, targetLang string
 	}
 
 	// Sending the request
-	resp, err := http.Post("http://localhost:5000/translate", "application/json", bytes.NewBuffer(jsonData))
+	resp, err := http.Post(libreTranslateURL+"/translate", "application/json", bytes.NewBuffer(jsonData))
 	if err != nil {
 		return "", fmt.Errorf("error while sending request: %v", err)
 	}
@@ -47,11 +92,27 @@ func translateByLibreTranslate(text string, sourceLang string, targetLang string
 	return result.TranslatedText, nil
 }
 
+// Translate translates Chinese text to Russian
 func Translate(chineseText string) string {
+	return TranslateTo(chineseText, "ru")
+}
+
+// TranslateTo translates Chinese text to the target language.
+// The glossary is used for Russian only.
+func TranslateTo(chineseText string, target string) string {
 	if chineseText == "" {
 		return chineseText
 	}
 
+	if target == "ru" {
+		glossaryMu.RLock()
+		term, ok := glossary[strings.TrimSpace(chineseText)]
+		glossaryMu.RUnlock()
+		if ok {
+			return term
+		}
+	}
+
 	// Ch to En
 	englishText, err := translateByLibreTranslate(chineseText, "zh", "en")
 	if err != nil {
@@ -60,15 +121,18 @@ func Translate(chineseText string) string {
 			"error: %v", chineseText, err)
 		return chineseText
 	}
+	if target == "en" {
+		return englishText
+	}
 
-	// En to Rus
-	russianText, err := translateByLibreTranslate(englishText, "en", "ru")
+	// En to the target language
+	translatedText, err := translateByLibreTranslate(englishText, "en", target)
 	if err != nil {
-		logging.TranslationsLogger.LogErrorF("Error while translating ro Russian\n "+
+		logging.TranslationsLogger.LogErrorF("Error while translating to %v\n "+
 			"English: %v\n"+
-			"error: %v", englishText, err)
+			"error: %v", target, englishText, err)
 		return englishText
 	}
 
-	return russianText
+	return translatedText
 }